@@ -0,0 +1,230 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// AggregateFunc is a function computed over a numeric field of the elements.
+type AggregateFunc int
+
+const (
+	AggregateCount AggregateFunc = iota // Number of elements with a numeric value
+	AggregateSum                        // Sum of the values
+	AggregateMin                        // Smallest value
+	AggregateMax                        // Largest value
+	AggregateAvg                        // Average of the values
+)
+
+// String returns the name of the function, e.g. "sum".
+func (this AggregateFunc) String() string {
+	switch this {
+	case AggregateCount:
+		return "count"
+	case AggregateSum:
+		return "sum"
+	case AggregateMin:
+		return "min"
+	case AggregateMax:
+		return "max"
+	case AggregateAvg:
+		return "avg"
+	}
+	return "unknown"
+}
+
+// Aggregation is a function applied to a field, given as a property path
+// such as "MyInt32" or "testproto.mysingle.myint64".
+type Aggregation struct {
+	Func  AggregateFunc
+	Field string
+}
+
+// Name returns the name of the aggregation, e.g. "sum(MyInt32)".
+func (this Aggregation) Name() string {
+	return this.Func.String() + "(" + this.Field + ")"
+}
+
+// AggregateGroup holds the results of the aggregations for one group.
+type AggregateGroup struct {
+	Key    string             // Group key, "<groupBy>=<value>", or "*" when not grouped
+	Value  interface{}        // Value of the group by field, nil if missing or not grouped
+	Count  int                // Number of elements in the group
+	Values map[string]float64 // Results by aggregation name
+}
+
+// aggregateState accumulates the values of one aggregation.
+type aggregateState struct {
+	count    int
+	sum      float64
+	min, max float64
+}
+
+// Aggregate groups the elements by the value of a property path and
+// computes the aggregations for every group. An empty groupBy puts all the
+// elements in a single group. Property paths match field names case
+// insensitively, ignoring underscores, and may start with the type name.
+//
+// Only numeric values take part in an aggregation. A group with no numeric
+// value for a field has no result for its aggregations. Nil elements are
+// ignored. Groups are returned in key order.
+//
+// Example:
+//
+//	groups := elems.Aggregate("status",
+//	    object.Aggregation{Func: object.AggregateAvg, Field: "latency"})
+func (this *Elements) Aggregate(groupBy string, aggregations ...Aggregation) []*AggregateGroup {
+	groups := make(map[string]*AggregateGroup)
+	states := make(map[string][]*aggregateState)
+	for _, o := range this.elements {
+		if o == nil || o.element == nil {
+			continue
+		}
+		key := "*"
+		var value interface{}
+		if groupBy != "" {
+			v := propertyValue(o.element, groupBy)
+			if v.IsValid() && v.CanInterface() {
+				value = v.Interface()
+			}
+			key = groupBy + "=" + groupValueString(value)
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &AggregateGroup{Key: key, Value: value, Values: make(map[string]float64)}
+			groups[key] = group
+			states[key] = make([]*aggregateState, len(aggregations))
+		}
+		group.Count++
+		for i, aggregation := range aggregations {
+			number, ok := numericValue(propertyValue(o.element, aggregation.Field))
+			if !ok {
+				continue
+			}
+			state := states[key][i]
+			if state == nil {
+				state = &aggregateState{min: number, max: number}
+				states[key][i] = state
+			}
+			state.add(number)
+		}
+	}
+
+	result := make([]*AggregateGroup, 0, len(groups))
+	for key, group := range groups {
+		for i, aggregation := range aggregations {
+			state := states[key][i]
+			if state != nil {
+				group.Values[aggregation.Name()] = state.result(aggregation.Func)
+			}
+		}
+		result = append(result, group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// AddAggregates computes Aggregate and writes the results into the metadata
+// key counts, next to "Total". The count of a group is stored under its key
+// and every aggregation under "<key>/<aggregation name>", for example
+// "status=Up" and "status=Up/avg(latency)". Existing counts with the same
+// keys are replaced. Returns the groups.
+func (this *Elements) AddAggregates(groupBy string, aggregations ...Aggregation) []*AggregateGroup {
+	groups := this.Aggregate(groupBy, aggregations...)
+	this.metadata = withTotal(this.metadata, len(this.elements))
+	counts := this.metadata.KeyCount.Counts
+	for _, group := range groups {
+		counts[group.Key] = float64(group.Count)
+		for name, value := range group.Values {
+			counts[group.Key+"/"+name] = value
+		}
+	}
+	return groups
+}
+
+// add accumulates a value.
+func (this *aggregateState) add(value float64) {
+	this.count++
+	this.sum += value
+	if value < this.min {
+		this.min = value
+	}
+	if value > this.max {
+		this.max = value
+	}
+}
+
+// result returns the value of an aggregate function.
+func (this *aggregateState) result(f AggregateFunc) float64 {
+	switch f {
+	case AggregateCount:
+		return float64(this.count)
+	case AggregateSum:
+		return this.sum
+	case AggregateMin:
+		return this.min
+	case AggregateMax:
+		return this.max
+	case AggregateAvg:
+		return this.sum / float64(this.count)
+	}
+	return 0
+}
+
+// propertyValue returns the value of a property path of an element, or an
+// invalid value if the path does not exist. A leading component naming the
+// element type is skipped.
+func propertyValue(elem interface{}, path string) reflect.Value {
+	t := reflect.TypeOf(elem)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return fieldByPath(elem, sortPath(path, t.Name()))
+}
+
+// numericValue returns a numeric value as a float64 and true, or false if
+// the value is not numeric.
+func numericValue(v reflect.Value) (float64, bool) {
+	if !v.IsValid() {
+		return 0, false
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// groupValueString formats a group value for use in a key.
+func groupValueString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	s, ok := value.(fmt.Stringer)
+	if ok {
+		return s.String()
+	}
+	return strings.TrimSpace(fmt.Sprint(value))
+}
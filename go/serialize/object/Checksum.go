@@ -0,0 +1,150 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// kindChecksum is the type prefix of a payload protected by a CRC32C trailer.
+const kindChecksum reflect.Kind = 67
+
+// ErrChecksumMismatch is returned when a payload protected by a checksum
+// was corrupted or truncated.
+var ErrChecksumMismatch = errors.New("checksum mismatch, payload is corrupted")
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+var checksumEnabled atomic.Bool
+
+var checksumRequired atomic.Bool
+
+// SetChecksum enables or disables appending a CRC32C trailer to payloads
+// produced by Elements.Serialize and the binary serializer. It is disabled
+// by default. Decoding does not depend on this setting, protected payloads
+// are always verified.
+func SetChecksum(enabled bool) {
+	checksumEnabled.Store(enabled)
+}
+
+// SetChecksumRequired makes VerifyChecksum, and so Elements.Deserialize,
+// ElemOf and the binary serializer, reject payloads without a checksum with
+// ErrChecksumMismatch. The kind prefix is not covered by the checksum, so
+// without this setting a corrupted prefix makes a protected payload look
+// unprotected. It is disabled by default, so payloads written before
+// checksums were enabled still decode.
+func SetChecksumRequired(required bool) {
+	checksumRequired.Store(required)
+}
+
+// AddChecksum protects a payload with a CRC32C (Castagnoli) trailer.
+// Format: kind prefix (int32), payload size (int32), payload and the
+// checksum of the payload (uint32).
+func AddChecksum(data []byte) []byte {
+	buff := make([]byte, 12+len(data))
+	location := 0
+	addInt32(int32(kindChecksum), &buff, &location)
+	addInt32(int32(len(data)), &buff, &location)
+	copy(buff[location:], data)
+	binary.BigEndian.PutUint32(buff[location+len(data):], crc32.Checksum(data, castagnoli))
+	return buff
+}
+
+// VerifyChecksum verifies a payload produced by AddChecksum and returns the
+// original payload, or ErrChecksumMismatch if it does not match its trailer.
+// Payloads without a checksum are returned as is, or rejected with
+// ErrChecksumMismatch if checksums are required, see SetChecksumRequired.
+func VerifyChecksum(data []byte) ([]byte, error) {
+	if !HasChecksum(data) {
+		if checksumRequired.Load() {
+			return nil, ErrChecksumMismatch
+		}
+		return data, nil
+	}
+	location := 4
+	return getChecksumBody(&data, &location)
+}
+
+// HasChecksum returns true if the payload starts with the checksum prefix.
+func HasChecksum(data []byte) bool {
+	if len(data) < 12 {
+		return false
+	}
+	location := 0
+	return reflect.Kind(getInt32(&data, &location)) == kindChecksum
+}
+
+// DataWithChecksum returns the serialized data protected by a CRC32C trailer.
+// Get and ElemOf verify the checksum before decoding.
+func (this *Object) DataWithChecksum() []byte {
+	return AddChecksum(this.Data())
+}
+
+// getChecksumBody reads a protected payload after its kind prefix, verifies
+// the trailer and returns the payload.
+func getChecksumBody(data *[]byte, location *int) ([]byte, error) {
+	size := int(getInt32(data, location))
+	if size < 0 || *location+size+4 > len(*data) {
+		return nil, ErrChecksumMismatch
+	}
+	body := (*data)[*location : *location+size]
+	*location += size
+	checksum := binary.BigEndian.Uint32((*data)[*location:])
+	*location += 4
+	if crc32.Checksum(body, castagnoli) != checksum {
+		return nil, ErrChecksumMismatch
+	}
+	return body, nil
+}
+
+// getChecksumObject verifies a protected payload and decodes a single value
+// from it, so that Get and ElemOf handle protected payloads transparently.
+func getChecksumObject(data *[]byte, location *int, registry ifs.IRegistry) (interface{}, error) {
+	body, err := getChecksumBody(data, location)
+	if err != nil {
+		return nil, err
+	}
+	return NewDecode(body, 0, registry).Get()
+}
+
+// Frame applies the configured compression and checksum to a payload.
+// It is used by Elements.Serialize and the binary serializer.
+func Frame(data []byte) ([]byte, error) {
+	data, err := Compress(data)
+	if err != nil {
+		return nil, err
+	}
+	if checksumEnabled.Load() {
+		data = AddChecksum(data)
+	}
+	return data, nil
+}
+
+// Unframe verifies and decompresses a payload produced by Frame.
+// Payloads without a checksum or compression are returned as is, unless
+// checksums are required.
+func Unframe(data []byte) ([]byte, error) {
+	data, err := VerifyChecksum(data)
+	if err != nil {
+		return nil, err
+	}
+	return Decompress(data)
+}
@@ -0,0 +1,318 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+)
+
+// ErrChunkTooLarge is returned when a single element does not fit in a chunk.
+var ErrChunkTooLarge = errors.New("element does not fit in a chunk")
+
+// SerializeChunks serializes the container into one or more chunks of at
+// most maxBytes each. Every chunk is a complete serialized Elements holding
+// a run of consecutive elements, the metadata, the query, the flags and the
+// headers, plus the HeaderChunkID, HeaderChunkSeq and HeaderChunkCount
+// headers. Use a Reassembler to rebuild the container from the chunks.
+//
+// Returns ErrChunkTooLarge if an element, with the chunk overhead, is larger
+// than maxBytes.
+func (this *Elements) SerializeChunks(maxBytes int) ([][]byte, error) {
+	id := newChunkID()
+	metadata := this.metadata
+	if metadata == nil {
+		metadata = withTotal(nil, len(this.elements))
+	}
+
+	// The overhead is the size of an empty chunk, the size of an element is
+	// its own encoding. Compression only applies when it makes the payload
+	// smaller, so the sum is an upper bound of the size of a chunk.
+	empty, err := this.chunk(id, nil, metadata, 0, 1).encode(nil)
+	if err != nil {
+		return nil, err
+	}
+	overhead := len(empty)
+	if checksumEnabled.Load() {
+		overhead += 12
+	}
+	changes := false
+	for _, o := range this.elements {
+		if o != nil && o.change != 0 {
+			changes = true
+			break
+		}
+	}
+	groups := make([][]*Element, 0)
+	var group []*Element
+	size := overhead
+	for _, o := range this.elements {
+		elemSize, err := elementSize(o, changes)
+		if err != nil {
+			return nil, err
+		}
+		if overhead+elemSize > maxBytes {
+			return nil, ErrChunkTooLarge
+		}
+		if len(group) > 0 && size+elemSize > maxBytes {
+			groups = append(groups, group)
+			group = nil
+			size = overhead
+		}
+		group = append(group, o)
+		size += elemSize
+	}
+	if len(group) > 0 || len(groups) == 0 {
+		groups = append(groups, group)
+	}
+
+	result := make([][]byte, len(groups))
+	for i, g := range groups {
+		result[i], err = this.chunk(id, g, metadata, i, len(groups)).Serialize()
+		if err != nil {
+			return nil, err
+		}
+		if len(result[i]) > maxBytes {
+			return nil, ErrChunkTooLarge
+		}
+	}
+	return result, nil
+}
+
+// elementSize returns the number of bytes an element adds to a serialized
+// container, including its entry in the errors section and its change type
+// if the container has changes.
+func elementSize(o *Element, changes bool) (int, error) {
+	obj := NewEncode()
+	err := addElement(o, obj)
+	if err != nil {
+		return 0, err
+	}
+	if o.error != nil {
+		obj.Add(0)
+		obj.Add(ErrorOf(o.error))
+	}
+	if changes {
+		obj.Add(int32(o.change))
+	}
+	return len(obj.Data()), nil
+}
+
+// chunk returns a copy of the container with the given elements, metadata
+// and chunk headers.
+func (this *Elements) chunk(id string, elements []*Element, metadata *l8api.L8MetaData, seq, count int) *Elements {
+	c := this.Clone(false)
+	c.elements = elements
+	c.keyIndex = nil
+	c.metadata = metadata
+	c.SetHeader(HeaderChunkID, id)
+	c.SetHeader(HeaderChunkSeq, int32(seq))
+	c.SetHeader(HeaderChunkCount, int32(count))
+	return c
+}
+
+// newChunkID returns a random id shared by the chunks of a container.
+func newChunkID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Default limits of a Reassembler, see SetLimits.
+const (
+	DefaultMaxPendingChunks = 1024            // Containers being reassembled at once
+	DefaultChunkTTL         = 5 * time.Minute // Time to receive all the chunks of a container
+	DefaultMaxChunks        = 65536           // Chunks of a single container
+)
+
+// Reassembler rebuilds containers from their chunks, which may arrive in any
+// order and interleaved with the chunks of other containers. Incomplete
+// containers are dropped when they expire or when too many are pending, so
+// lost chunks cannot exhaust memory. It is safe for concurrent use.
+type Reassembler struct {
+	registry   ifs.IRegistry
+	mtx        *sync.Mutex
+	pending    map[string]*chunkSet
+	maxPending int
+	maxChunks  int
+	ttl        time.Duration
+}
+
+// chunkSet holds the chunks of a container received so far.
+type chunkSet struct {
+	count   int
+	chunks  map[int]*Elements
+	created time.Time
+}
+
+// NewReassembler creates a reassembler that deserializes chunks with the
+// given registry, with the default limits.
+func NewReassembler(registry ifs.IRegistry) *Reassembler {
+	return &Reassembler{registry: registry, mtx: &sync.Mutex{}, pending: make(map[string]*chunkSet),
+		maxPending: DefaultMaxPendingChunks, maxChunks: DefaultMaxChunks, ttl: DefaultChunkTTL}
+}
+
+// SetLimits sets the maximum number of containers being reassembled and the
+// time to receive all the chunks of a container, after which its chunks are
+// discarded. When the maximum is reached, the oldest container is discarded
+// to make room for a new one. Zero disables a limit.
+func (this *Reassembler) SetLimits(maxPending int, ttl time.Duration) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.maxPending = maxPending
+	this.ttl = ttl
+}
+
+// SetMaxChunks sets the maximum number of chunks of a container. Chunks
+// that announce more are rejected by Add. Zero disables the limit.
+func (this *Reassembler) SetMaxChunks(maxChunks int) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.maxChunks = maxChunks
+}
+
+// Add deserializes a chunk. When it completes its container, the rebuilt
+// container is returned, without the chunk headers, otherwise nil. A
+// payload that is not a chunk is returned as is. Duplicate chunks are
+// ignored, chunks of a container with more chunks than allowed by
+// SetMaxChunks are rejected.
+func (this *Reassembler) Add(data []byte) (*Elements, error) {
+	chunk := &Elements{}
+	err := chunk.Deserialize(data, this.registry)
+	if err != nil {
+		return nil, err
+	}
+	id := chunk.HeaderString(HeaderChunkID)
+	if id == "" {
+		return chunk, nil
+	}
+	seq, _ := chunk.headers[HeaderChunkSeq].(int32)
+	count, _ := chunk.headers[HeaderChunkCount].(int32)
+	if count <= 0 || seq < 0 || seq >= count {
+		return nil, errors.New("invalid chunk " + strconv.Itoa(int(seq)) + " of " + strconv.Itoa(int(count)))
+	}
+
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	if this.maxChunks > 0 && int(count) > this.maxChunks {
+		return nil, errors.New("chunk " + id + " has " + strconv.Itoa(int(count)) +
+			" chunks, more than the maximum of " + strconv.Itoa(this.maxChunks))
+	}
+	this.expire()
+	set, ok := this.pending[id]
+	if !ok {
+		if this.maxPending > 0 && len(this.pending) >= this.maxPending {
+			this.discardOldest()
+		}
+		set = &chunkSet{count: int(count), chunks: make(map[int]*Elements), created: time.Now()}
+		this.pending[id] = set
+	}
+	if set.count != int(count) {
+		return nil, errors.New("chunk " + id + " has inconsistent chunk counts")
+	}
+	set.chunks[int(seq)] = chunk
+	if len(set.chunks) < set.count {
+		return nil, nil
+	}
+	delete(this.pending, id)
+	return set.assemble(), nil
+}
+
+// Missing returns the sequence numbers of the chunks of a container that
+// were not received yet, or nil if no chunk of the container was received.
+func (this *Reassembler) Missing(id string) []int {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.expire()
+	set, ok := this.pending[id]
+	if !ok {
+		return nil
+	}
+	result := make([]int, 0, set.count-len(set.chunks))
+	for i := 0; i < set.count; i++ {
+		if _, ok := set.chunks[i]; !ok {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// Pending returns the ids of the containers that are not complete, in order.
+func (this *Reassembler) Pending() []string {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.expire()
+	result := make([]string, 0, len(this.pending))
+	for id := range this.pending {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Discard drops the chunks received for a container, e.g. after a timeout.
+func (this *Reassembler) Discard(id string) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	delete(this.pending, id)
+}
+
+// expire discards the containers older than the TTL.
+func (this *Reassembler) expire() {
+	if this.ttl <= 0 {
+		return
+	}
+	now := time.Now()
+	for id, set := range this.pending {
+		if now.Sub(set.created) > this.ttl {
+			delete(this.pending, id)
+		}
+	}
+}
+
+// discardOldest discards the container whose first chunk arrived first.
+func (this *Reassembler) discardOldest() {
+	oldest := ""
+	var created time.Time
+	for id, set := range this.pending {
+		if oldest == "" || set.created.Before(created) {
+			oldest, created = id, set.created
+		}
+	}
+	delete(this.pending, oldest)
+}
+
+// assemble concatenates the elements of the chunks in sequence order, taking
+// everything else from the first chunk.
+func (this *chunkSet) assemble() *Elements {
+	result := this.chunks[0]
+	result.RemoveHeader(HeaderChunkID)
+	result.RemoveHeader(HeaderChunkSeq)
+	result.RemoveHeader(HeaderChunkCount)
+	result.headers = copyHeaders(result.headers)
+	for i := 1; i < this.count; i++ {
+		result.elements = append(result.elements, this.chunks[i].elements...)
+	}
+	result.keyIndex = nil
+	return result
+}
@@ -0,0 +1,543 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/saichler/l8types/go/ifs"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// kindColumnar is the type prefix of a columnar encoded slice of protobuf
+// messages. It is placed above the range of reflect.Kind values so it never
+// collides with the regular type prefixes.
+const kindColumnar reflect.Kind = 64
+
+// errInvalidColumnar is returned when a columnar payload is truncated or
+// corrupted.
+var errInvalidColumnar = errors.New("Invalid columnar payload")
+
+// Encodings of an integer column, the smallest one is picked per column.
+const (
+	columnPlain byte = 0 // Each value as a zigzag varint
+	columnDelta byte = 1 // First value followed by zigzag varint deltas
+	columnRLE   byte = 2 // Pairs of zigzag varint value and run length
+)
+
+// AddColumnar serializes a homogeneous slice of protobuf messages in columnar
+// form. The slice is transposed into one column per scalar field, each column
+// is dictionary, delta or run-length encoded, and every field that cannot be
+// transposed (messages, lists, maps, fields with presence) is kept in a
+// dictionary encoded residual column. Rows of near-identical messages
+// therefore compress down to a few bytes per distinct value.
+//
+// The result is decoded transparently by Get, which returns a typed slice
+// of the message pointers, e.g. []*MyProto.
+//
+// Returns an error if any element is not a protobuf message or if the
+// elements are not all of the same message type.
+func (this *Object) AddColumnar(any interface{}) error {
+	if this.redaction != nil {
+		any = this.redaction.Apply(any)
+	}
+	this.addKind(kindColumnar)
+	return addColumnar(any, this.data, this.location)
+}
+
+// addColumnar writes the columnar body of a slice of protobuf messages.
+// Format: row count (int32), type name (string), presence column,
+// residual column, column count, then per column the field number and
+// the encoded values. Nil or empty slices are encoded as -1.
+func addColumnar(any interface{}, data *[]byte, location *int) error {
+	if any == nil {
+		addInt32(int32(-1), data, location)
+		return nil
+	}
+	slice := reflect.ValueOf(any)
+	if slice.Kind() != reflect.Slice {
+		return errors.New("Columnar encoding expects a slice, got " + slice.Kind().String())
+	}
+	if slice.Len() == 0 {
+		addInt32(int32(-1), data, location)
+		return nil
+	}
+
+	rows := slice.Len()
+	msgs := make([]protoreflect.Message, rows)
+	var md protoreflect.MessageDescriptor
+	for i := 0; i < rows; i++ {
+		elem := slice.Index(i)
+		if elem.Kind() == reflect.Ptr && elem.IsNil() {
+			continue
+		}
+		pb, ok := elem.Interface().(proto.Message)
+		if !ok {
+			return errors.New("Columnar encoding element " + strconv.Itoa(i) + " is not a proto message")
+		}
+		msgs[i] = pb.ProtoReflect()
+		if md == nil {
+			md = msgs[i].Descriptor()
+		} else if md.FullName() != msgs[i].Descriptor().FullName() {
+			return errors.New("Columnar encoding expects a single type, found " +
+				string(md.FullName()) + " and " + string(msgs[i].Descriptor().FullName()))
+		}
+	}
+	if md == nil {
+		return errors.New("Columnar encoding cannot resolve the type of a slice with only nil elements")
+	}
+
+	addInt32(int32(rows), data, location)
+	addString(string(md.Name()), data, location)
+
+	present := make([]uint64, rows)
+	residual := make([][]byte, rows)
+	for i, m := range msgs {
+		if m == nil {
+			continue
+		}
+		present[i] = 1
+		res := m.New()
+		m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+			if !isColumnField(fd) {
+				res.Set(fd, v)
+			}
+			return true
+		})
+		res.SetUnknown(m.GetUnknown())
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(res.Interface())
+		if err != nil {
+			return errors.New("Failed To marshal proto " + string(md.Name()) + " in columnar object:" + err.Error())
+		}
+		residual[i] = b
+	}
+	addIntColumn(present, data, location)
+	addBytesColumn(residual, data, location)
+
+	fields := make([]protoreflect.FieldDescriptor, 0)
+	for i := 0; i < md.Fields().Len(); i++ {
+		fd := md.Fields().Get(i)
+		if !isColumnField(fd) {
+			continue
+		}
+		for _, m := range msgs {
+			if m != nil && m.Has(fd) {
+				fields = append(fields, fd)
+				break
+			}
+		}
+	}
+
+	addUVarint(uint64(len(fields)), data, location)
+	for _, fd := range fields {
+		addUVarint(uint64(fd.Number()), data, location)
+		switch fd.Kind() {
+		case protoreflect.StringKind:
+			values := make([]string, rows)
+			for i, m := range msgs {
+				if m != nil {
+					values[i] = m.Get(fd).String()
+				}
+			}
+			addStringColumn(values, data, location)
+		case protoreflect.BytesKind:
+			values := make([][]byte, rows)
+			for i, m := range msgs {
+				if m != nil {
+					values[i] = m.Get(fd).Bytes()
+				}
+			}
+			addBytesColumn(values, data, location)
+		default:
+			values := make([]uint64, rows)
+			for i, m := range msgs {
+				if m != nil {
+					values[i] = columnValue(fd, m.Get(fd))
+				}
+			}
+			addIntColumn(values, data, location)
+		}
+	}
+	return nil
+}
+
+// getColumnar deserializes a columnar encoded slice of protobuf messages.
+// The message type is resolved through the registry and the rows are
+// rebuilt from the residual column before the field columns are applied.
+func getColumnar(data *[]byte, location *int, registry ifs.IRegistry) (interface{}, error) {
+	if *location+4 > len(*data) {
+		return nil, errInvalidColumnar
+	}
+	rows := int(getInt32(data, location))
+	if rows == -1 || rows == 0 {
+		return nil, nil
+	}
+	// Every decoded row takes at least a pointer, bound them like
+	// decompressed payloads
+	if rows < 0 || int64(rows) > MaxDataSize/8 {
+		return nil, errors.New("Invalid columnar row count " + strconv.Itoa(rows))
+	}
+	typeName, err := getColumnString(data, location)
+	if err != nil {
+		return nil, err
+	}
+	info, err := registry.Info(typeName)
+	if err != nil {
+		return nil, errors.New("Unknown proto name " + typeName + " in registry, please register it.")
+	}
+
+	present, err := getIntColumn(rows, data, location)
+	if err != nil {
+		return nil, err
+	}
+	residual, err := getBytesColumn(rows, data, location)
+	if err != nil {
+		return nil, err
+	}
+
+	sample, err := info.NewInstance()
+	if err != nil {
+		return nil, errors.New("Error proto name " + typeName + " in registry, cannot instantiate.")
+	}
+	md := sample.(proto.Message).ProtoReflect().Descriptor()
+
+	msgs := make([]protoreflect.Message, rows)
+	for i := 0; i < rows; i++ {
+		if present[i] == 0 {
+			continue
+		}
+		pb, err := info.NewInstance()
+		if err != nil {
+			return nil, errors.New("Error proto name " + typeName + " in registry, cannot instantiate.")
+		}
+		err = proto.Unmarshal(residual[i], pb.(proto.Message))
+		if err != nil {
+			return nil, errors.New("Failed To unmarshal proto " + typeName + ":" + err.Error())
+		}
+		msgs[i] = pb.(proto.Message).ProtoReflect()
+	}
+
+	columns, err := getColumnUVarint(data, location)
+	if err != nil {
+		return nil, err
+	}
+	for c := uint64(0); c < columns; c++ {
+		field, err := getColumnUVarint(data, location)
+		if err != nil {
+			return nil, err
+		}
+		number := protoreflect.FieldNumber(field)
+		fd := md.Fields().ByNumber(number)
+		if fd == nil || !isColumnField(fd) {
+			return nil, errors.New("Unknown column field " + strconv.Itoa(int(number)) + " for proto " + typeName)
+		}
+		switch fd.Kind() {
+		case protoreflect.StringKind:
+			values, err := getStringColumn(rows, data, location)
+			if err != nil {
+				return nil, err
+			}
+			for i, m := range msgs {
+				if m != nil {
+					m.Set(fd, protoreflect.ValueOfString(values[i]))
+				}
+			}
+		case protoreflect.BytesKind:
+			values, err := getBytesColumn(rows, data, location)
+			if err != nil {
+				return nil, err
+			}
+			for i, m := range msgs {
+				if m != nil && len(values[i]) > 0 {
+					m.Set(fd, protoreflect.ValueOfBytes(values[i]))
+				}
+			}
+		default:
+			values, err := getIntColumn(rows, data, location)
+			if err != nil {
+				return nil, err
+			}
+			for i, m := range msgs {
+				if m != nil {
+					m.Set(fd, valueOfColumn(fd, values[i]))
+				}
+			}
+		}
+	}
+
+	result := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(sample)), rows, rows)
+	for i, m := range msgs {
+		if m != nil {
+			result.Index(i).Set(reflect.ValueOf(m.Interface()))
+		}
+	}
+	return result.Interface(), nil
+}
+
+// isColumnField returns true if the field is a singular scalar without
+// explicit presence, which can be transposed into a column and restored
+// without changing the message.
+func isColumnField(fd protoreflect.FieldDescriptor) bool {
+	return fd.Cardinality() != protoreflect.Repeated &&
+		fd.Message() == nil &&
+		!fd.HasPresence()
+}
+
+// columnValue converts a scalar field value to the raw 64 bit representation
+// stored in an integer column. Signed values are kept in two's complement and
+// floating point values as their IEEE 754 bits.
+func columnValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) uint64 {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	case protoreflect.EnumKind:
+		return uint64(int64(v.Enum()))
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return uint64(v.Int())
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return v.Uint()
+	case protoreflect.FloatKind:
+		return uint64(math.Float32bits(float32(v.Float())))
+	case protoreflect.DoubleKind:
+		return math.Float64bits(v.Float())
+	}
+	return 0
+}
+
+// valueOfColumn is the inverse of columnValue.
+func valueOfColumn(fd protoreflect.FieldDescriptor, u uint64) protoreflect.Value {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(u != 0)
+	case protoreflect.EnumKind:
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(int32(int64(u))))
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(int32(int64(u)))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protoreflect.ValueOfInt64(int64(u))
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return protoreflect.ValueOfUint32(uint32(u))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return protoreflect.ValueOfUint64(u)
+	case protoreflect.FloatKind:
+		return protoreflect.ValueOfFloat32(math.Float32frombits(uint32(u)))
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(math.Float64frombits(u))
+	}
+	return protoreflect.Value{}
+}
+
+// addIntColumn encodes a column of raw 64 bit values with each of the
+// plain, delta and run-length encodings and keeps the smallest result.
+// Format: encoding (byte) followed by the encoded values.
+func addIntColumn(values []uint64, data *[]byte, location *int) {
+	var best []byte
+	for _, encoding := range []byte{columnPlain, columnDelta, columnRLE} {
+		buff := make([]byte, 64)
+		loc := 0
+		encodeIntColumn(encoding, values, &buff, &loc)
+		if best == nil || loc < len(best) {
+			best = buff[0:loc]
+		}
+	}
+	checkAndEnlarge(data, location, len(best))
+	copy((*data)[*location:*location+len(best)], best)
+	*location += len(best)
+}
+
+// encodeIntColumn writes the values of an integer column with the given encoding.
+func encodeIntColumn(encoding byte, values []uint64, data *[]byte, location *int) {
+	addByte(encoding, data, location)
+	switch encoding {
+	case columnPlain:
+		for _, v := range values {
+			addUVarint(zigzag(int64(v)), data, location)
+		}
+	case columnDelta:
+		var prev uint64
+		for _, v := range values {
+			addUVarint(zigzag(int64(v-prev)), data, location)
+			prev = v
+		}
+	case columnRLE:
+		for i := 0; i < len(values); {
+			run := 1
+			for i+run < len(values) && values[i+run] == values[i] {
+				run++
+			}
+			addUVarint(zigzag(int64(values[i])), data, location)
+			addUVarint(uint64(run), data, location)
+			i += run
+		}
+	}
+}
+
+// getIntColumn decodes an integer column of the given number of rows.
+func getIntColumn(rows int, data *[]byte, location *int) ([]uint64, error) {
+	if *location >= len(*data) {
+		return nil, errInvalidColumnar
+	}
+	values := make([]uint64, rows)
+	encoding := getByte(data, location)
+	switch encoding {
+	case columnPlain, columnDelta:
+		var prev uint64
+		for i := 0; i < rows; i++ {
+			u, err := getColumnUVarint(data, location)
+			if err != nil {
+				return nil, err
+			}
+			if encoding == columnPlain {
+				values[i] = uint64(unzigzag(u))
+				continue
+			}
+			prev += uint64(unzigzag(u))
+			values[i] = prev
+		}
+	case columnRLE:
+		for i := 0; i < rows; {
+			u, err := getColumnUVarint(data, location)
+			if err != nil {
+				return nil, err
+			}
+			run, err := getColumnUVarint(data, location)
+			if err != nil || run == 0 {
+				return nil, errInvalidColumnar
+			}
+			for j := uint64(0); j < run && i < rows; j++ {
+				values[i] = uint64(unzigzag(u))
+				i++
+			}
+		}
+	default:
+		return nil, errors.New("Unknown column encoding " + strconv.Itoa(int(encoding)))
+	}
+	return values, nil
+}
+
+// addStringColumn dictionary encodes a column of strings.
+// Format: dictionary size (varint), the distinct strings, then an integer
+// column of dictionary indexes.
+func addStringColumn(values []string, data *[]byte, location *int) {
+	dictionary := make(map[string]uint64)
+	words := make([]string, 0)
+	indexes := make([]uint64, len(values))
+	for i, v := range values {
+		index, ok := dictionary[v]
+		if !ok {
+			index = uint64(len(words))
+			dictionary[v] = index
+			words = append(words, v)
+		}
+		indexes[i] = index
+	}
+	addUVarint(uint64(len(words)), data, location)
+	for _, word := range words {
+		addString(word, data, location)
+	}
+	addIntColumn(indexes, data, location)
+}
+
+// getStringColumn decodes a dictionary encoded column of strings.
+func getStringColumn(rows int, data *[]byte, location *int) ([]string, error) {
+	size, err := getColumnUVarint(data, location)
+	if err != nil {
+		return nil, err
+	}
+	// Every word takes at least its 4 byte length
+	if size > uint64(len(*data)-*location)/4 {
+		return nil, errInvalidColumnar
+	}
+	words := make([]string, int(size))
+	for i := range words {
+		words[i], err = getColumnString(data, location)
+		if err != nil {
+			return nil, err
+		}
+	}
+	indexes, err := getIntColumn(rows, data, location)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, rows)
+	for i, index := range indexes {
+		if index >= uint64(len(words)) {
+			return nil, errInvalidColumnar
+		}
+		values[i] = words[index]
+	}
+	return values, nil
+}
+
+// addBytesColumn dictionary encodes a column of byte slices, using the same
+// format as addStringColumn.
+func addBytesColumn(values [][]byte, data *[]byte, location *int) {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = string(v)
+	}
+	addStringColumn(strs, data, location)
+}
+
+// getBytesColumn decodes a dictionary encoded column of byte slices.
+func getBytesColumn(rows int, data *[]byte, location *int) ([][]byte, error) {
+	strs, err := getStringColumn(rows, data, location)
+	if err != nil {
+		return nil, err
+	}
+	values := make([][]byte, rows)
+	for i, s := range strs {
+		values[i] = []byte(s)
+	}
+	return values, nil
+}
+
+// getColumnUVarint reads a varint, or returns an error if the payload is
+// truncated.
+func getColumnUVarint(data *[]byte, location *int) (uint64, error) {
+	if *location >= len(*data) {
+		return 0, errInvalidColumnar
+	}
+	result, n := binary.Uvarint((*data)[*location:])
+	if n <= 0 {
+		return 0, errInvalidColumnar
+	}
+	*location += n
+	return result, nil
+}
+
+// getColumnString reads a string, or returns an error if the payload is
+// truncated.
+func getColumnString(data *[]byte, location *int) (string, error) {
+	if *location+4 > len(*data) {
+		return "", errInvalidColumnar
+	}
+	size := int(getInt32(data, location))
+	if size < 0 || *location+size > len(*data) {
+		return "", errInvalidColumnar
+	}
+	result := string((*data)[*location : *location+size])
+	*location += size
+	return result, nil
+}
@@ -0,0 +1,256 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// kindCompressed is the type prefix of a compressed payload. Uncompressed
+// payloads always start with a reflect.Kind prefix, so the two can be told
+// apart and payloads written before compression existed still decode.
+const kindCompressed reflect.Kind = 66
+
+// Compressor is a pluggable compression algorithm. The ID is written into
+// every compressed payload to select the algorithm on decode, so it must be
+// unique and must not change once payloads are persisted.
+type Compressor interface {
+	ID() byte
+	Compress([]byte) ([]byte, error)
+	Decompress([]byte) ([]byte, error)
+}
+
+// MaxDataSize is the largest payload, in bytes, that decompression produces.
+// Larger payloads are rejected with ErrDataTooLarge, so a small compressed
+// payload cannot expand into an unbounded allocation.
+var MaxDataSize int64 = 64 * 1024 * 1024
+
+// ErrDataTooLarge is returned when a payload decompresses to more than
+// MaxDataSize bytes.
+var ErrDataTooLarge = errors.New("decompressed payload exceeds the maximum data size")
+
+// Built-in compressors from the standard library.
+var (
+	Deflate Compressor = &deflateCompressor{}
+	GZip    Compressor = &gzipCompressor{}
+)
+
+var (
+	compressMtx       = &sync.RWMutex{}
+	compressors       = map[byte]Compressor{Deflate.ID(): Deflate, GZip.ID(): GZip}
+	compressor        Compressor
+	compressThreshold int
+)
+
+// RegisterCompressor makes a compressor available for decoding and for
+// SetCompression. Registering a compressor with an existing ID replaces it.
+func RegisterCompressor(c Compressor) {
+	compressMtx.Lock()
+	defer compressMtx.Unlock()
+	compressors[c.ID()] = c
+}
+
+// SetCompression enables transparent compression of payloads produced by
+// Elements.Serialize and the binary serializer. Payloads smaller than the
+// threshold in bytes are left uncompressed, as are payloads that would not
+// get smaller. A nil compressor disables compression, which is the default.
+//
+// Decoding does not depend on this setting, compressed payloads are always
+// recognized and decompressed with the compressor registered for their ID.
+func SetCompression(c Compressor, threshold int) {
+	compressMtx.Lock()
+	defer compressMtx.Unlock()
+	if c != nil {
+		compressors[c.ID()] = c
+	}
+	compressor = c
+	compressThreshold = threshold
+}
+
+// Compress compresses the payload with the configured compressor if it is
+// at least the configured threshold in size. The compressed payload format is:
+// kind prefix (int32), compressor ID (byte), compressed size (int32) and the
+// compressed bytes. If compression is disabled, below the threshold or does
+// not reduce the size, the payload is returned as is.
+func Compress(data []byte) ([]byte, error) {
+	compressMtx.RLock()
+	c, threshold := compressor, compressThreshold
+	compressMtx.RUnlock()
+	if c == nil || len(data) < threshold || IsCompressed(data) {
+		return data, nil
+	}
+	compressed, err := c.Compress(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(compressed)+9 >= len(data) {
+		return data, nil
+	}
+	buff := make([]byte, 9+len(compressed))
+	location := 0
+	addInt32(int32(kindCompressed), &buff, &location)
+	addByte(c.ID(), &buff, &location)
+	addInt32(int32(len(compressed)), &buff, &location)
+	copy(buff[location:], compressed)
+	return buff, nil
+}
+
+// Decompress returns the original payload of a payload produced by Compress.
+// Payloads that are not compressed are returned as is.
+func Decompress(data []byte) ([]byte, error) {
+	if !IsCompressed(data) {
+		return data, nil
+	}
+	location := 0
+	return getCompressed(&data, &location)
+}
+
+// IsCompressed returns true if the payload starts with the compressed prefix.
+func IsCompressed(data []byte) bool {
+	if len(data) < 9 {
+		return false
+	}
+	location := 0
+	return reflect.Kind(getInt32(&data, &location)) == kindCompressed
+}
+
+// getCompressed reads a compressed payload, after or including its kind
+// prefix, and returns the decompressed bytes.
+func getCompressed(data *[]byte, location *int) ([]byte, error) {
+	if reflect.Kind(getInt32(data, location)) != kindCompressed {
+		return nil, errors.New("Payload is not compressed")
+	}
+	return getCompressedBody(data, location)
+}
+
+// getCompressedBody reads the compressor ID and the compressed bytes and
+// returns the decompressed bytes.
+func getCompressedBody(data *[]byte, location *int) ([]byte, error) {
+	id := getByte(data, location)
+	size := int(getInt32(data, location))
+	if size < 0 || *location+size > len(*data) {
+		return nil, errors.New("Compressed payload is truncated")
+	}
+	compressed := (*data)[*location : *location+size]
+	*location += size
+
+	compressMtx.RLock()
+	c, ok := compressors[id]
+	compressMtx.RUnlock()
+	if !ok {
+		return nil, errors.New("Unknown compressor id " + strconv.Itoa(int(id)) + ", please register it.")
+	}
+	plain, err := c.Decompress(compressed)
+	if err != nil {
+		return nil, err
+	}
+	// Registered compressors may not limit their output
+	if int64(len(plain)) > MaxDataSize {
+		return nil, ErrDataTooLarge
+	}
+	return plain, nil
+}
+
+// getCompressedObject decodes a single value from a compressed payload, so
+// that Get and ElemOf handle compressed payloads transparently.
+func getCompressedObject(data *[]byte, location *int, registry ifs.IRegistry) (interface{}, error) {
+	plain, err := getCompressedBody(data, location)
+	if err != nil {
+		return nil, err
+	}
+	return NewDecode(plain, 0, registry).Get()
+}
+
+// deflateCompressor implements Compressor with raw DEFLATE.
+type deflateCompressor struct{}
+
+func (this *deflateCompressor) ID() byte {
+	return 1
+}
+
+func (this *deflateCompressor) Compress(data []byte) ([]byte, error) {
+	buff := &bytes.Buffer{}
+	w, err := flate.NewWriter(buff, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+func (this *deflateCompressor) Decompress(data []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+	return readLimited(r)
+}
+
+// gzipCompressor implements Compressor with gzip.
+type gzipCompressor struct{}
+
+func (this *gzipCompressor) ID() byte {
+	return 2
+}
+
+func (this *gzipCompressor) Compress(data []byte) ([]byte, error) {
+	buff := &bytes.Buffer{}
+	w := gzip.NewWriter(buff)
+	_, err := w.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+func (this *gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return readLimited(r)
+}
+
+// readLimited reads a decompressing reader to the end, failing with
+// ErrDataTooLarge after MaxDataSize bytes.
+func readLimited(r io.Reader) ([]byte, error) {
+	result, err := io.ReadAll(io.LimitReader(r, MaxDataSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(result)) > MaxDataSize {
+		return nil, ErrDataTooLarge
+	}
+	return result, nil
+}
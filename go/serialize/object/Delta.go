@@ -0,0 +1,381 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"reflect"
+	"strconv"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// kindDelta is the type prefix of a delta payload.
+const kindDelta reflect.Kind = 71
+
+// ErrDeltaBaseMismatch is returned when a delta is applied to a message
+// other than the base it was encoded against.
+var ErrDeltaBaseMismatch = errors.New("delta does not match the base message")
+
+// ErrInvalidDelta is returned when a payload is not a valid delta.
+var ErrInvalidDelta = errors.New("invalid delta payload")
+
+// Delta operations on a single field.
+const (
+	deltaClear      byte = 1 // Clear the field
+	deltaSet        byte = 2 // Replace the field with the value in the partial message
+	deltaMessage    byte = 3 // Apply nested operations to a message field
+	deltaMapSet     byte = 4 // Set the map entries of the partial message
+	deltaMapDelete  byte = 5 // Delete the map keys of the partial message
+	deltaListAppend byte = 6 // Append the list items of the partial message
+)
+
+// deltaOp is an operation on a field. The partial message holds the values
+// of the operation in the same field.
+type deltaOp struct {
+	op      byte
+	field   protoreflect.FieldNumber
+	partial protoreflect.Message
+	nested  []*deltaOp
+}
+
+// EncodeDelta returns the changes that turn base into current: the changed
+// and removed fields, and item appends and entry edits for lists and maps.
+// Nested messages are encoded field by field. The delta carries a
+// fingerprint of base, so ApplyDelta detects a delta applied to the wrong
+// base. Both messages must be of the same type.
+//
+// Example, sending only the edits of a large config to a replica:
+//
+//	delta, err := object.EncodeDelta(previous, config)
+//	...
+//	config, err := object.ApplyDelta(previous, delta)
+func EncodeDelta(base, current proto.Message) ([]byte, error) {
+	if base == nil || current == nil || reflect.ValueOf(base).IsNil() || reflect.ValueOf(current).IsNil() {
+		return nil, errors.New("delta messages cannot be nil")
+	}
+	a, b := base.ProtoReflect(), current.ProtoReflect()
+	if a.Descriptor().FullName() != b.Descriptor().FullName() {
+		return nil, errors.New("delta messages have different types " +
+			string(a.Descriptor().FullName()) + " and " + string(b.Descriptor().FullName()))
+	}
+	fingerprint, err := deltaFingerprint(base)
+	if err != nil {
+		return nil, err
+	}
+	ops := deltaOps(a, b)
+
+	data := make([]byte, 256)
+	location := 0
+	addInt32(int32(kindDelta), &data, &location)
+	addString(string(a.Descriptor().FullName()), &data, &location)
+	addDeltaBytes(fingerprint, &data, &location)
+	err = addDeltaOps(ops, &data, &location)
+	if err != nil {
+		return nil, err
+	}
+	return data[:location], nil
+}
+
+// ApplyDelta returns a new message with the changes of a delta applied to
+// base, which is not modified. Returns ErrDeltaBaseMismatch if base is not
+// the message the delta was encoded against, or ErrInvalidDelta if the
+// payload is not a valid delta.
+func ApplyDelta(base proto.Message, delta []byte) (proto.Message, error) {
+	if base == nil || reflect.ValueOf(base).IsNil() {
+		return nil, errors.New("delta base cannot be nil")
+	}
+	location := 0
+	kind, err := getDeltaInt32(&delta, &location)
+	if err != nil || reflect.Kind(kind) != kindDelta {
+		return nil, ErrInvalidDelta
+	}
+	// The type name is written by addString, in the same format as bytes
+	name, err := getDeltaBytes(&delta, &location)
+	if err != nil {
+		return nil, err
+	}
+	if string(name) != string(base.ProtoReflect().Descriptor().FullName()) {
+		return nil, ErrDeltaBaseMismatch
+	}
+	fingerprint, err := deltaFingerprint(base)
+	if err != nil {
+		return nil, err
+	}
+	baseFingerprint, err := getDeltaBytes(&delta, &location)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(baseFingerprint, fingerprint) {
+		return nil, ErrDeltaBaseMismatch
+	}
+	result := proto.Clone(base)
+	err = applyDeltaOps(result.ProtoReflect(), &delta, &location)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// deltaFingerprint returns the SHA-256 digest of the deterministic encoding
+// of a message.
+func deltaFingerprint(msg proto.Message) ([]byte, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(data)
+	return digest[:], nil
+}
+
+// deltaOps returns the operations that turn a into b.
+func deltaOps(a, b protoreflect.Message) []*deltaOp {
+	var ops []*deltaOp
+	fields := a.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		hasA, hasB := a.Has(fd), b.Has(fd)
+		if !hasA && !hasB {
+			continue
+		}
+		if !hasB {
+			ops = append(ops, &deltaOp{op: deltaClear, field: fd.Number()})
+			continue
+		}
+		if !hasA {
+			ops = append(ops, setOp(a, fd, b.Get(fd)))
+			continue
+		}
+		switch {
+		case fd.IsMap():
+			ops = append(ops, mapOps(a, fd, a.Get(fd).Map(), b.Get(fd).Map())...)
+		case fd.IsList():
+			ops = append(ops, listOps(a, fd, a.Get(fd).List(), b.Get(fd).List())...)
+		case fd.Message() != nil:
+			nested := deltaOps(a.Get(fd).Message(), b.Get(fd).Message())
+			if len(nested) > 0 {
+				ops = append(ops, &deltaOp{op: deltaMessage, field: fd.Number(), nested: nested})
+			}
+		default:
+			if !a.Get(fd).Equal(b.Get(fd)) {
+				ops = append(ops, setOp(a, fd, b.Get(fd)))
+			}
+		}
+	}
+	return ops
+}
+
+// setOp returns an operation replacing a field with a value.
+func setOp(m protoreflect.Message, fd protoreflect.FieldDescriptor, value protoreflect.Value) *deltaOp {
+	partial := m.New()
+	partial.Set(fd, value)
+	return &deltaOp{op: deltaSet, field: fd.Number(), partial: partial}
+}
+
+// mapOps returns the operations setting the new and changed entries of a
+// map, and deleting its removed keys.
+func mapOps(m protoreflect.Message, fd protoreflect.FieldDescriptor, a, b protoreflect.Map) []*deltaOp {
+	set := m.New()
+	deleted := m.New()
+	b.Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+		if !a.Has(key) || !a.Get(key).Equal(value) {
+			set.Mutable(fd).Map().Set(key, value)
+		}
+		return true
+	})
+	a.Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+		if !b.Has(key) {
+			deletedMap := deleted.Mutable(fd).Map()
+			deletedMap.Set(key, deletedMap.NewValue())
+		}
+		return true
+	})
+	var ops []*deltaOp
+	if set.Has(fd) {
+		ops = append(ops, &deltaOp{op: deltaMapSet, field: fd.Number(), partial: set})
+	}
+	if deleted.Has(fd) {
+		ops = append(ops, &deltaOp{op: deltaMapDelete, field: fd.Number(), partial: deleted})
+	}
+	return ops
+}
+
+// listOps returns an append operation if the old list is a prefix of the new
+// one, otherwise an operation replacing the list.
+func listOps(m protoreflect.Message, fd protoreflect.FieldDescriptor, a, b protoreflect.List) []*deltaOp {
+	if b.Len() < a.Len() {
+		return []*deltaOp{setOp(m, fd, protoreflect.ValueOfList(b))}
+	}
+	for i := 0; i < a.Len(); i++ {
+		if !a.Get(i).Equal(b.Get(i)) {
+			return []*deltaOp{setOp(m, fd, protoreflect.ValueOfList(b))}
+		}
+	}
+	if b.Len() == a.Len() {
+		return nil
+	}
+	partial := m.New()
+	list := partial.Mutable(fd).List()
+	for i := a.Len(); i < b.Len(); i++ {
+		list.Append(b.Get(i))
+	}
+	return []*deltaOp{{op: deltaListAppend, field: fd.Number(), partial: partial}}
+}
+
+// addDeltaOps serializes operations.
+// Format: the operation count (int32), then per operation the op (byte), the
+// field number (int32) and either the nested operations or the encoded
+// partial message (int32 size and bytes), except for clear.
+func addDeltaOps(ops []*deltaOp, data *[]byte, location *int) error {
+	addInt32(int32(len(ops)), data, location)
+	for _, op := range ops {
+		addByte(op.op, data, location)
+		addInt32(int32(op.field), data, location)
+		switch op.op {
+		case deltaClear:
+		case deltaMessage:
+			err := addDeltaOps(op.nested, data, location)
+			if err != nil {
+				return err
+			}
+		default:
+			partial, err := proto.MarshalOptions{Deterministic: true}.Marshal(op.partial.Interface())
+			if err != nil {
+				return err
+			}
+			addDeltaBytes(partial, data, location)
+		}
+	}
+	return nil
+}
+
+// deltaOpFits returns true if the op can be applied to the kind of the field.
+func deltaOpFits(op byte, fd protoreflect.FieldDescriptor) bool {
+	switch op {
+	case deltaClear, deltaSet:
+		return true
+	case deltaMessage:
+		return fd.Message() != nil && !fd.IsList() && !fd.IsMap()
+	case deltaMapSet, deltaMapDelete:
+		return fd.IsMap()
+	case deltaListAppend:
+		return fd.IsList()
+	}
+	return false
+}
+
+// applyDeltaOps reads operations written by addDeltaOps and applies them.
+func applyDeltaOps(m protoreflect.Message, data *[]byte, location *int) error {
+	size, err := getDeltaInt32(data, location)
+	if err != nil || size < 0 {
+		return ErrInvalidDelta
+	}
+	for i := 0; i < int(size); i++ {
+		if *location >= len(*data) {
+			return ErrInvalidDelta
+		}
+		op := getByte(data, location)
+		field, err := getDeltaInt32(data, location)
+		if err != nil {
+			return err
+		}
+		number := protoreflect.FieldNumber(field)
+		fd := m.Descriptor().Fields().ByNumber(number)
+		if fd == nil {
+			return errors.New("delta field " + strconv.Itoa(int(number)) + " does not exist in " +
+				string(m.Descriptor().FullName()))
+		}
+		if !deltaOpFits(op, fd) {
+			return ErrInvalidDelta
+		}
+		if op == deltaClear {
+			m.Clear(fd)
+			continue
+		}
+		if op == deltaMessage {
+			err := applyDeltaOps(m.Mutable(fd).Message(), data, location)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		b, err := getDeltaBytes(data, location)
+		if err != nil {
+			return err
+		}
+		partial := m.New()
+		err = proto.Unmarshal(b, partial.Interface())
+		if err != nil {
+			return ErrInvalidDelta
+		}
+		switch op {
+		case deltaSet:
+			m.Set(fd, partial.Get(fd))
+		case deltaMapSet:
+			target := m.Mutable(fd).Map()
+			partial.Get(fd).Map().Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+				target.Set(key, value)
+				return true
+			})
+		case deltaMapDelete:
+			target := m.Mutable(fd).Map()
+			partial.Get(fd).Map().Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+				target.Clear(key)
+				return true
+			})
+		case deltaListAppend:
+			target := m.Mutable(fd).List()
+			items := partial.Get(fd).List()
+			for j := 0; j < items.Len(); j++ {
+				target.Append(items.Get(j))
+			}
+		default:
+			return ErrInvalidDelta
+		}
+	}
+	return nil
+}
+
+// addDeltaBytes writes a byte slice with its int32 size.
+func addDeltaBytes(b []byte, data *[]byte, location *int) {
+	addInt32(int32(len(b)), data, location)
+	checkAndEnlarge(data, location, len(b))
+	copy((*data)[*location:], b)
+	*location += len(b)
+}
+
+// getDeltaBytes reads a byte slice written by addDeltaBytes, or returns
+// ErrInvalidDelta if the payload is truncated.
+func getDeltaBytes(data *[]byte, location *int) ([]byte, error) {
+	size, err := getDeltaInt32(data, location)
+	if err != nil || size < 0 || *location+int(size) > len(*data) {
+		return nil, ErrInvalidDelta
+	}
+	result := (*data)[*location : *location+int(size)]
+	*location += int(size)
+	return result, nil
+}
+
+// getDeltaInt32 reads an int32, or returns ErrInvalidDelta if the payload
+// is truncated.
+func getDeltaInt32(data *[]byte, location *int) (int32, error) {
+	if *location+4 > len(*data) {
+		return 0, ErrInvalidDelta
+	}
+	return getInt32(data, location), nil
+}
@@ -0,0 +1,216 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/saichler/l8types/go/ifs"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// kindChange is the type prefix of a serialized Change.
+const kindChange reflect.Kind = 70
+
+// ChangeType tells how an element differs between two snapshots.
+type ChangeType int32
+
+const (
+	ChangeAdded   ChangeType = 1 // The element exists only in the new snapshot
+	ChangeRemoved ChangeType = 2 // The element exists only in the old snapshot
+	ChangeChanged ChangeType = 3 // The element exists in both, with different values
+)
+
+// String returns the name of the change type, e.g. "added".
+func (this ChangeType) String() string {
+	switch this {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeChanged:
+		return "changed"
+	}
+	return "unknown"
+}
+
+// Change is a difference of one element between two snapshots. It can be
+// added to an Object or an Elements container like any other value.
+type Change struct {
+	Type  ChangeType  // How the element differs
+	Key   interface{} // Key of the element
+	Old   interface{} // Value in the old snapshot, nil if added
+	New   interface{} // Value in the new snapshot, nil if removed
+	Paths []string    // Changed field paths, e.g. "my_single.my_string", if changed
+}
+
+// Diff compares two snapshots, matching their elements by key, and returns
+// a container with a *Change element, keyed with the element key, for
+// every element that was added, removed or changed. Added and changed
+// elements come first, in the order of the new snapshot, followed by the
+// removed elements in the order of the old one. Elements without a key are
+// ignored and, if several elements share a key, the first one is used.
+//
+// Protobuf values are compared with proto.Equal and their changed fields are
+// listed, other values with reflect.DeepEqual. The metadata key counts hold
+// the number of "Added", "Removed" and "Changed" elements.
+//
+// Example, sending only what changed since the last tick:
+//
+//	changes := object.Diff(previous, current)
+//	data, err := changes.Serialize()
+func Diff(old, new ifs.IElements) *Elements {
+	oldValues := keyedValues(old)
+	newValues := keyedValues(new)
+	result := &Elements{}
+	var added, removed, changed int
+	for _, key := range newValues.keys {
+		newValue := newValues.values[key]
+		oldValue, ok := oldValues.values[key]
+		if !ok {
+			result.Add(&Change{Type: ChangeAdded, Key: key, New: newValue}, key, nil)
+			added++
+			continue
+		}
+		paths, equal := compareElements(oldValue, newValue)
+		if !equal {
+			result.Add(&Change{Type: ChangeChanged, Key: key, Old: oldValue, New: newValue, Paths: paths}, key, nil)
+			changed++
+		}
+	}
+	for _, key := range oldValues.keys {
+		if _, ok := newValues.values[key]; !ok {
+			result.Add(&Change{Type: ChangeRemoved, Key: key, Old: oldValues.values[key]}, key, nil)
+			removed++
+		}
+	}
+	result.metadata = withTotal(nil, len(result.elements))
+	counts := result.metadata.KeyCount.Counts
+	counts["Added"] = float64(added)
+	counts["Removed"] = float64(removed)
+	counts["Changed"] = float64(changed)
+	return result
+}
+
+// keyed holds the elements of a snapshot by key, with the keys in order.
+type keyed struct {
+	keys   []interface{}
+	values map[interface{}]interface{}
+}
+
+// keyedValues indexes the elements of a snapshot by their hashable keys.
+func keyedValues(elems ifs.IElements) *keyed {
+	result := &keyed{values: make(map[interface{}]interface{})}
+	if elems == nil {
+		return result
+	}
+	values := elems.Elements()
+	for i, key := range elems.Keys() {
+		if !hashableKey(key) {
+			continue
+		}
+		if _, ok := result.values[key]; ok {
+			continue
+		}
+		result.keys = append(result.keys, key)
+		result.values[key] = values[i]
+	}
+	return result
+}
+
+// compareElements returns whether two values are equal and, for protobuf
+// messages of the same type, the paths of the fields that differ.
+func compareElements(a, b interface{}) ([]string, bool) {
+	pa, okA := a.(proto.Message)
+	pb, okB := b.(proto.Message)
+	if okA && okB && !reflect.ValueOf(a).IsNil() && !reflect.ValueOf(b).IsNil() {
+		if proto.Equal(pa, pb) {
+			return nil, true
+		}
+		ma, mb := pa.ProtoReflect(), pb.ProtoReflect()
+		if ma.Descriptor().FullName() != mb.Descriptor().FullName() {
+			return nil, false
+		}
+		return changedPaths(ma, mb, ""), false
+	}
+	return nil, reflect.DeepEqual(a, b)
+}
+
+// changedPaths returns the paths of the fields that differ between two
+// messages of the same type. Nested messages are compared field by field,
+// lists and maps as a whole.
+func changedPaths(a, b protoreflect.Message, prefix string) []string {
+	var result []string
+	fields := a.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		path := prefix + string(fd.Name())
+		if !a.Has(fd) && !b.Has(fd) {
+			continue
+		}
+		if fd.Message() != nil && !fd.IsList() && !fd.IsMap() && a.Has(fd) && b.Has(fd) {
+			result = append(result, changedPaths(a.Get(fd).Message(), b.Get(fd).Message(), path+".")...)
+			continue
+		}
+		if a.Has(fd) != b.Has(fd) || !a.Get(fd).Equal(b.Get(fd)) {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// addChange serializes a change.
+// Format: type (int32), key, old and new values (typed values), the path
+// count (int32) and the paths (strings). The values are written with the
+// object, so its redaction policy applies to them.
+func addChange(change *Change, obj *Object) error {
+	addInt32(int32(change.Type), obj.data, obj.location)
+	for _, value := range []interface{}{change.Key, change.Old, change.New} {
+		err := obj.Add(value)
+		if err != nil {
+			return err
+		}
+	}
+	addInt32(int32(len(change.Paths)), obj.data, obj.location)
+	for _, path := range change.Paths {
+		addString(path, obj.data, obj.location)
+	}
+	return nil
+}
+
+// getChange deserializes a change written by addChange.
+func getChange(data *[]byte, location *int, registry ifs.IRegistry) (*Change, error) {
+	result := &Change{Type: ChangeType(getInt32(data, location))}
+	obj := newDecode(data, location, registry)
+	values := make([]interface{}, 3)
+	for i := range values {
+		value, err := obj.Get()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	result.Key, result.Old, result.New = values[0], values[1], values[2]
+	size := int(getInt32(data, location))
+	if size < 0 {
+		return nil, errors.New("invalid change path count")
+	}
+	for i := 0; i < size; i++ {
+		result.Paths = append(result.Paths, getString(data, location))
+	}
+	return result, nil
+}
@@ -0,0 +1,266 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// kindError is the type prefix of a serialized ElementError.
+const kindError reflect.Kind = 69
+
+// ElementError is a structured error that survives serialization. Besides
+// the message it carries a code, a retryable flag, optional details and a
+// chain of causes. Codes can be bound to sentinel errors with RegisterError,
+// so that errors.Is matches the sentinel on the receiving side.
+type ElementError struct {
+	Code      int32             // Application error code, 0 if none
+	Message   string            // Human readable message
+	Retryable bool              // True if the operation may succeed when retried
+	Details   map[string]string // Optional details, e.g. the resource name
+	Cause     error             // The error that caused this one, if any
+}
+
+// registeredError binds a code to a sentinel error.
+type registeredError struct {
+	code      int32
+	sentinel  error
+	retryable bool
+}
+
+var (
+	errorsMtx  = &sync.RWMutex{}
+	errorCodes = make(map[int32]*registeredError)
+)
+
+// RegisterError binds an error code to a sentinel error. Errors matching
+// the sentinel are serialized with the code and the retryable flag, and
+// deserialized errors with the code match the sentinel with errors.Is.
+func RegisterError(code int32, sentinel error, retryable bool) {
+	errorsMtx.Lock()
+	defer errorsMtx.Unlock()
+	errorCodes[code] = &registeredError{code: code, sentinel: sentinel, retryable: retryable}
+}
+
+// NewElementError creates a structured error with the given code and message.
+// If the code is registered, the retryable flag is taken from the registration.
+func NewElementError(code int32, message string) *ElementError {
+	result := &ElementError{Code: code, Message: message}
+	if reg := registeredByCode(code); reg != nil {
+		result.Retryable = reg.retryable
+	}
+	return result
+}
+
+// ErrorOf converts any error to an ElementError. The code and retryable flag
+// are resolved from the registered sentinels and wrapped errors become the
+// chain of causes. An error joining several errors, e.g. with errors.Join,
+// takes the code, retryable flag and details of the first joined error that
+// has a code, and the first joined error as its cause. Returns nil for a nil
+// error.
+func ErrorOf(err error) *ElementError {
+	if err == nil {
+		return nil
+	}
+	ee, ok := err.(*ElementError)
+	if ok {
+		return ee
+	}
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		return joinedErrorOf(err, multi.Unwrap())
+	}
+	result := &ElementError{Message: err.Error()}
+	if reg := registeredByError(err); reg != nil {
+		result.Code = reg.code
+		result.Retryable = reg.retryable
+	}
+	cause := errors.Unwrap(err)
+	if cause != nil {
+		result.Cause = ErrorOf(cause)
+	}
+	return result
+}
+
+// joinedErrorOf converts an error joining several errors.
+func joinedErrorOf(err error, joined []error) *ElementError {
+	var causes []*ElementError
+	for _, e := range joined {
+		if e != nil {
+			causes = append(causes, ErrorOf(e))
+		}
+	}
+	if len(causes) == 1 {
+		return causes[0]
+	}
+	result := &ElementError{Message: err.Error()}
+	for _, cause := range causes {
+		if cause.Code != 0 {
+			result.Code = cause.Code
+			result.Retryable = cause.Retryable
+			result.Details = cause.Details
+			break
+		}
+	}
+	if len(causes) > 0 {
+		result.Cause = causes[0]
+	}
+	return result
+}
+
+// IsRetryable returns true if the error, or any error it wraps, is an
+// ElementError marked as retryable.
+func IsRetryable(err error) bool {
+	var ee *ElementError
+	for err != nil {
+		if errors.As(err, &ee) {
+			if ee.Retryable {
+				return true
+			}
+			err = ee.Cause
+			continue
+		}
+		return false
+	}
+	return false
+}
+
+// Error returns the message of the error.
+func (this *ElementError) Error() string {
+	return this.Message
+}
+
+// Unwrap returns the cause of the error.
+func (this *ElementError) Unwrap() error {
+	return this.Cause
+}
+
+// Is matches another ElementError with the same non zero code, or the
+// sentinel error registered for the code.
+func (this *ElementError) Is(target error) bool {
+	if this.Code == 0 {
+		return false
+	}
+	other, ok := target.(*ElementError)
+	if ok {
+		return other.Code == this.Code
+	}
+	reg := registeredByCode(this.Code)
+	return reg != nil && reg.sentinel == target
+}
+
+// As sets target to the sentinel error registered for the code, if the
+// sentinel is assignable to what target points to.
+func (this *ElementError) As(target interface{}) bool {
+	if this.Code == 0 || target == nil {
+		return false
+	}
+	reg := registeredByCode(this.Code)
+	if reg == nil {
+		return false
+	}
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return false
+	}
+	sentinel := reflect.ValueOf(reg.sentinel)
+	if !sentinel.Type().AssignableTo(value.Elem().Type()) {
+		return false
+	}
+	value.Elem().Set(sentinel)
+	return true
+}
+
+// WithDetail adds a detail to the error and returns it.
+func (this *ElementError) WithDetail(key, value string) *ElementError {
+	if this.Details == nil {
+		this.Details = make(map[string]string)
+	}
+	this.Details[key] = value
+	return this
+}
+
+// WithCause sets the cause of the error and returns it.
+func (this *ElementError) WithCause(cause error) *ElementError {
+	this.Cause = cause
+	return this
+}
+
+// registeredByCode returns the registration of a code, or nil.
+func registeredByCode(code int32) *registeredError {
+	errorsMtx.RLock()
+	defer errorsMtx.RUnlock()
+	return errorCodes[code]
+}
+
+// registeredByError returns the registration of the first sentinel the
+// error matches, or nil.
+func registeredByError(err error) *registeredError {
+	errorsMtx.RLock()
+	defer errorsMtx.RUnlock()
+	for _, reg := range errorCodes {
+		if errors.Is(err, reg.sentinel) {
+			return reg
+		}
+	}
+	return nil
+}
+
+// addElementError serializes an error.
+// Format: code (int32), message (string), retryable (bool), details count
+// (int32) with key/value strings in key order, and a bool telling whether
+// a cause follows, in which case the cause is written the same way.
+func addElementError(err *ElementError, data *[]byte, location *int) {
+	addInt32(err.Code, data, location)
+	addString(err.Message, data, location)
+	addBool(err.Retryable, data, location)
+	keys := make([]string, 0, len(err.Details))
+	for key := range err.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	addInt32(int32(len(keys)), data, location)
+	for _, key := range keys {
+		addString(key, data, location)
+		addString(err.Details[key], data, location)
+	}
+	cause := ErrorOf(err.Cause)
+	addBool(cause != nil, data, location)
+	if cause != nil {
+		addElementError(cause, data, location)
+	}
+}
+
+// getElementError deserializes an error written by addElementError.
+func getElementError(data *[]byte, location *int) *ElementError {
+	result := &ElementError{}
+	result.Code = getInt32(data, location)
+	result.Message = getString(data, location)
+	result.Retryable = getBool(data, location)
+	size := int(getInt32(data, location))
+	if size > 0 {
+		result.Details = make(map[string]string, size)
+		for i := 0; i < size; i++ {
+			key := getString(data, location)
+			result.Details[key] = getString(data, location)
+		}
+	}
+	if getBool(data, location) {
+		result.Cause = getElementError(data, location)
+	}
+	return result
+}
@@ -17,10 +17,12 @@ package object
 import (
 	"errors"
 	"reflect"
+	"strconv"
 
 	"github.com/saichler/l8ql/go/gsql/interpreter"
 	"github.com/saichler/l8types/go/ifs"
 	"github.com/saichler/l8types/go/types/l8api"
+	"google.golang.org/protobuf/proto"
 )
 
 // Elements is a container for multiple serializable objects with support for
@@ -35,6 +37,7 @@ import (
 //   - Metadata for statistics and pagination
 //   - Notification flags for event-driven systems
 //   - Replica support for distributed systems
+//   - Headers for cross-cutting request context
 type Elements struct {
 	elements     []*Element        // Slice of element wrappers
 	query        ifs.IQuery        // Parsed query object
@@ -42,10 +45,28 @@ type Elements struct {
 	metadata     *l8api.L8MetaData // Metadata including counts and pagination info
 	notification bool              // Flag indicating this is a notification
 
-	isReplica bool // Flag indicating this is a replica request
-	replica   byte // Replica number for distributed systems
+	isReplica bool       // Flag indicating this is a replica request
+	replica   byte       // Replica number for distributed systems
+	replicas  ReplicaSet // Replicas targeted by the request, if more than one
+
+	filterMode      bool // Filter mode as carried in a deserialized payload
+	filterModeKnown bool // True if filterMode was carried in the payload
+
+	headers map[string]interface{} // Request/response context, e.g. trace IDs
+
+	source   string // ID of the source of a sequenced notification
+	sequence uint64 // Sequence number of a notification from its source, 0 if none
+
+	keyIndex map[interface{}]int // Lazily built index of element keys
 }
 
+// Flags of the Elements mode section.
+const (
+	flagNotification uint32 = 1 << iota // The container is a notification
+	flagReplica                         // The container is a replica request
+	flagFilterMode                      // The container is in filter mode
+)
+
 // Element wraps a single value with its associated key and any error
 // that occurred during processing. This allows batch operations to
 // track success/failure per item.
@@ -53,6 +74,7 @@ type Element struct {
 	element interface{} // The actual data value
 	key     interface{} // Optional key for map-like access
 	error   error       // Error associated with this element, if any
+	change  ChangeType  // Change carried by a notification, 0 if none
 }
 
 // NewQuery creates a new Elements container with an L8QL query string.
@@ -87,31 +109,100 @@ func NewNotify(any interface{}) ifs.IElements {
 
 // NewReplicaRequest creates a replica request from existing elements.
 // This is used in distributed systems to send data to replica nodes
-// with a specific replica number for identification.
+// with a specific replica number for identification. The request is a
+// shallow Clone, so adding to or removing from it does not affect elems.
 //
 // Parameters:
 //   - elems: The source elements to replicate
 //   - replica: The replica number (0-255) identifying the target replica
 func NewReplicaRequest(elems ifs.IElements, replica byte) ifs.IElements {
-	c := clone(elems)
+	c := elems.(*Elements).Clone(false)
 	c.replica = replica
+	c.replicas = 0
 	c.isReplica = true
 	return c
 }
 
-// clone creates a shallow copy of an Elements container.
-// The element slice is shared, not copied.
-func clone(e ifs.IElements) *Elements {
-	old := e.(*Elements)
+// Clone creates a copy of the container with every field copied: the
+// elements with their keys, errors and change types, the metadata, the
+// query, the notification and replica flags, the replica set, the headers
+// and the notification sequence. The element list is always a new slice, so
+// adding or removing elements in the copy does not affect the original.
+//
+// A shallow clone shares the element values, errors, metadata and query with
+// the original. A deep clone also copies protobuf element values and keys,
+// the metadata and the query with proto.Clone, and ElementError errors with
+// their details and causes, so the copy can be mutated freely. The parsed
+// query of a deep clone is rebuilt from its copy by Query. Other values and
+// errors are shared in both cases.
+func (this *Elements) Clone(deep bool) *Elements {
 	c := &Elements{}
-	c.elements = old.elements
-	c.query = old.query
-	c.pquery = old.pquery
-	c.notification = old.notification
-	c.replica = old.replica
+	if this.elements != nil {
+		c.elements = make([]*Element, len(this.elements))
+		for i, o := range this.elements {
+			if o == nil {
+				continue
+			}
+			e := &Element{element: o.element, key: o.key, error: o.error, change: o.change}
+			if deep {
+				e.element = cloneValue(o.element)
+				e.key = cloneValue(o.key)
+				e.error = cloneError(o.error)
+			}
+			c.elements[i] = e
+		}
+	}
+	c.query = this.query
+	c.pquery = this.pquery
+	c.metadata = this.metadata
+	if deep {
+		c.query = nil
+		if this.pquery != nil {
+			c.pquery = proto.Clone(this.pquery).(*l8api.L8Query)
+		}
+		if this.metadata != nil {
+			c.metadata = proto.Clone(this.metadata).(*l8api.L8MetaData)
+		}
+	}
+	c.notification = this.notification
+	c.isReplica = this.isReplica
+	c.replica = this.replica
+	c.replicas = this.replicas
+	c.filterMode = this.filterMode
+	c.filterModeKnown = this.filterModeKnown
+	c.headers = copyHeaders(this.headers)
+	c.source = this.source
+	c.sequence = this.sequence
 	return c
 }
 
+// cloneValue returns a deep copy of a protobuf message, or the value as is.
+func cloneValue(any interface{}) interface{} {
+	pb, ok := any.(proto.Message)
+	if !ok || reflect.ValueOf(any).IsNil() {
+		return any
+	}
+	return proto.Clone(pb)
+}
+
+// cloneError returns a deep copy of an ElementError, with its details and
+// the ElementError causes of its chain, or the error as is.
+func cloneError(err error) error {
+	ee, ok := err.(*ElementError)
+	if !ok || ee == nil {
+		return err
+	}
+	c := *ee
+	if ee.Details != nil {
+		c.Details = make(map[string]string, len(ee.Details))
+		for key, value := range ee.Details {
+			c.Details[key] = value
+		}
+	}
+	c.Cause = cloneError(ee.Cause)
+	return &c
+}
+
 // New creates a new Elements container from any Go value.
 // It automatically handles slices and maps by extracting their elements
 // into the internal element list. For other types, the value is stored
@@ -193,6 +284,7 @@ func (this *Elements) Add(elem interface{}, key interface{}, err error) {
 		this.elements = make([]*Element, 0)
 	}
 	this.elements = append(this.elements, mobject)
+	this.indexKey(mobject, len(this.elements)-1)
 }
 
 // Elements returns all stored values as a slice of interface{}.
@@ -251,27 +343,47 @@ func (this *Elements) Error() error {
 //   - For each element: value, key, error message (empty string if no error)
 //   - Metadata (auto-generated if not set)
 //   - Query (if present)
+//   - Mode section: notification, replica and filter mode flags, replica number
+//   - Headers section: header count followed by key/value pairs
+//   - Notification section: source, sequence and per element change types
+//   - Replica set section: the targeted replicas as a bitmask
+//   - Errors section: index and structured error of each failed element
+//
+// The result is compressed if compression is enabled with SetCompression
+// and the payload reaches the configured threshold, and protected by a
+// CRC32C trailer if enabled with SetChecksum.
 //
 // Returns the serialized bytes and any error encountered.
 func (this *Elements) Serialize() ([]byte, error) {
+	return this.serialize(nil)
+}
+
+// SerializeRedacted serializes the container like Serialize, with the fields
+// selected by the redaction policy blanked or hashed. The elements themselves
+// are not mutated.
+func (this *Elements) SerializeRedacted(policy *RedactionPolicy) ([]byte, error) {
+	return this.serialize(policy)
+}
+
+// serialize writes the container with an optional redaction policy.
+func (this *Elements) serialize(policy *RedactionPolicy) ([]byte, error) {
+	data, err := this.encode(policy)
+	if err != nil {
+		return nil, err
+	}
+	return Frame(data)
+}
+
+// encode writes the container with an optional redaction policy, before
+// compression and checksum.
+func (this *Elements) encode(policy *RedactionPolicy) ([]byte, error) {
 	obj := NewEncode()
+	obj.SetRedaction(policy)
 	obj.Add(len(this.elements))
 	var err error
 
 	for _, o := range this.elements {
-		err = obj.Add(o.element)
-		if err != nil {
-			return nil, err
-		}
-		err = obj.Add(o.key)
-		if err != nil {
-			return nil, err
-		}
-		if o.error != nil {
-			err = obj.Add(o.error.Error())
-		} else {
-			err = obj.Add("")
-		}
+		err = addElement(o, obj)
 		if err != nil {
 			return nil, err
 		}
@@ -286,9 +398,123 @@ func (this *Elements) Serialize() ([]byte, error) {
 	obj.Add(this.metadata)
 
 	obj.Add(this.pquery)
+
+	this.addModes(obj)
+	err = this.addHeaders(obj)
+	if err != nil {
+		return nil, err
+	}
+	this.addNotification(obj)
+	obj.Add(uint64(this.replicas))
+	this.addErrors(obj)
 	return obj.Data(), nil
 }
 
+// addElement writes an element: its value, its key and its error message,
+// or an empty string if it has none. The structured errors are written in
+// the errors section, see addErrors, so readers that predate them still
+// find a string here.
+func addElement(o *Element, obj *Object) error {
+	err := obj.Add(o.element)
+	if err != nil {
+		return err
+	}
+	err = obj.Add(o.key)
+	if err != nil {
+		return err
+	}
+	if o.error != nil {
+		return obj.Add(o.error.Error())
+	}
+	return obj.Add("")
+}
+
+// addErrors writes the errors section: the number of elements with an
+// error (int), then the index (int) and the ElementError of each.
+func (this *Elements) addErrors(obj *Object) {
+	count := 0
+	for _, o := range this.elements {
+		if o != nil && o.error != nil {
+			count++
+		}
+	}
+	obj.Add(count)
+	for i, o := range this.elements {
+		if o != nil && o.error != nil {
+			obj.Add(i)
+			obj.Add(ErrorOf(o.error))
+		}
+	}
+}
+
+// getErrors reads the errors section written by addErrors, replacing the
+// errors rebuilt from the element messages.
+func (this *Elements) getErrors(obj *Object) error {
+	c, err := obj.Get()
+	if err != nil {
+		return err
+	}
+	count, ok := c.(int)
+	if !ok || count < 0 || count > len(this.elements) {
+		return errors.New("invalid elements errors section")
+	}
+	for j := 0; j < count; j++ {
+		index, err := obj.Get()
+		if err != nil {
+			return err
+		}
+		value, err := obj.Get()
+		if err != nil {
+			return err
+		}
+		i, ok1 := index.(int)
+		ee, ok2 := value.(*ElementError)
+		if !ok1 || !ok2 || i < 0 || i >= len(this.elements) {
+			return errors.New("invalid elements errors section")
+		}
+		this.elements[i].error = ee
+	}
+	return nil
+}
+
+// addModes writes the mode section: the flags (uint32) and the replica number.
+func (this *Elements) addModes(obj *Object) {
+	flags := uint32(0)
+	if this.notification {
+		flags |= flagNotification
+	}
+	if this.isReplica {
+		flags |= flagReplica
+	}
+	if this.IsFilterMode() {
+		flags |= flagFilterMode
+	}
+	obj.Add(flags)
+	obj.Add(this.replica)
+}
+
+// getModes reads the mode section written by addModes.
+func (this *Elements) getModes(obj *Object) error {
+	f, err := obj.Get()
+	if err != nil {
+		return err
+	}
+	flags, ok := f.(uint32)
+	if !ok {
+		return errors.New("invalid elements mode section")
+	}
+	r, err := obj.Get()
+	if err != nil {
+		return err
+	}
+	this.replica, _ = r.(byte)
+	this.notification = flags&flagNotification != 0
+	this.isReplica = flags&flagReplica != 0
+	this.filterMode = flags&flagFilterMode != 0
+	this.filterModeKnown = true
+	return nil
+}
+
 // PQuery returns the protocol buffer query representation.
 func (this *Elements) PQuery() *l8api.L8Query {
 	return this.pquery
@@ -296,12 +522,18 @@ func (this *Elements) PQuery() *l8api.L8Query {
 
 // Deserialize reconstructs the Elements container from a byte slice.
 // It reverses the Serialize() operation, restoring all elements,
-// metadata, and query information.
+// metadata, and query information. Element errors are restored as
+// *ElementError, keeping their code, details and causes. Returns ErrChecksumMismatch if the
+// payload carries a checksum that does not match.
 //
 // Parameters:
 //   - data: The serialized byte slice
 //   - r: Type registry for resolving complex types
 func (this *Elements) Deserialize(data []byte, r ifs.IRegistry) error {
+	data, err := Unframe(data)
+	if err != nil {
+		return err
+	}
 	location := 0
 	obj := NewDecode(data, location, r)
 
@@ -311,6 +543,7 @@ func (this *Elements) Deserialize(data []byte, r ifs.IRegistry) error {
 	}
 	size := s.(int)
 	this.elements = make([]*Element, size)
+	this.keyIndex = nil
 	var eMsg interface{}
 	for i := 0; i < size; i++ {
 		elem := &Element{}
@@ -326,9 +559,14 @@ func (this *Elements) Deserialize(data []byte, r ifs.IRegistry) error {
 		if err != nil {
 			return err
 		}
-		errMsg := eMsg.(string)
-		if errMsg != "" {
-			elem.error = errors.New(errMsg)
+		switch errValue := eMsg.(type) {
+		case *ElementError:
+			elem.error = errValue
+		case string:
+			// The structured error, if any, is in the errors section
+			if errValue != "" {
+				elem.error = errors.New(errValue)
+			}
 		}
 		this.elements[i] = elem
 	}
@@ -344,6 +582,46 @@ func (this *Elements) Deserialize(data []byte, r ifs.IRegistry) error {
 		return err
 	}
 	this.pquery, _ = pq.(*l8api.L8Query)
+
+	// Payloads written before the mode section existed end here
+	if obj.Location() < len(data) {
+		err = this.getModes(obj)
+		if err != nil {
+			return err
+		}
+	}
+	if obj.Location() < len(data) {
+		err = this.getHeaders(obj)
+		if err != nil {
+			return err
+		}
+	}
+	this.source, this.sequence = "", 0
+	if obj.Location() < len(data) {
+		err = this.getNotification(obj)
+		if err != nil {
+			return err
+		}
+	}
+	this.replicas = 0
+	if obj.Location() < len(data) {
+		r, err := obj.Get()
+		if err != nil {
+			return err
+		}
+		replicas, ok := r.(uint64)
+		if !ok {
+			return errors.New("invalid elements replica set section")
+		}
+		this.replicas = ReplicaSet(replicas)
+	}
+	// Payloads written before the errors section carry the messages only
+	if obj.Location() < len(data) {
+		err = this.getErrors(obj)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -363,7 +641,8 @@ func (this *Elements) IsReplica() bool {
 }
 
 // Append adds all elements from another Elements container to this one.
-// Keys and errors are not preserved; only the values are copied.
+// Keys and errors are not preserved; only the values are copied. Use Merge
+// to keep keys, errors and metadata.
 func (this *Elements) Append(elements ifs.IElements) {
 	if elements == nil {
 		return
@@ -384,7 +663,8 @@ func (this *Elements) Append(elements ifs.IElements) {
 // of having a list wrapper type.
 //
 // Returns the first element if no list type is registered, or an error if
-// the container is empty.
+// the container is empty, the elements are not pointers to structs, an
+// element does not fit the list or the list type has no "List" field.
 func (this *Elements) AsList(r ifs.IRegistry) (interface{}, error) {
 	if len(this.elements) == 0 {
 		return nil, errors.New("elements is empty")
@@ -392,7 +672,11 @@ func (this *Elements) AsList(r ifs.IRegistry) (interface{}, error) {
 	if this.elements[0] == nil || this.elements[0].element == nil {
 		return nil, errors.New("element is nil")
 	}
-	listName := reflect.ValueOf(this.elements[0].element).Elem().Type().Name() + "List"
+	first := reflect.TypeOf(this.elements[0].element)
+	if first.Kind() != reflect.Ptr || first.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("element " + first.String() + " is not a pointer to a struct")
+	}
+	listName := first.Elem().Name() + "List"
 	info, err := r.Info(listName)
 
 	if err != nil {
@@ -405,20 +689,76 @@ func (this *Elements) AsList(r ifs.IRegistry) (interface{}, error) {
 	}
 	v := reflect.ValueOf(listItem).Elem()
 	f := v.FieldByName("List")
+	if !f.IsValid() || f.Kind() != reflect.Slice || !f.CanSet() {
+		return nil, errors.New(listName + " has no List field")
+	}
+	itemType := f.Type().Elem()
 	newList := reflect.MakeSlice(f.Type(), len(this.elements), len(this.elements))
 	for i := 0; i < len(this.elements); i++ {
-		newList.Index(i).Set(reflect.ValueOf(this.elements[i].element))
+		if this.elements[i] == nil || this.elements[i].element == nil {
+			continue
+		}
+		item := reflect.ValueOf(this.elements[i].element)
+		if !item.Type().AssignableTo(itemType) {
+			return nil, errors.New("element " + strconv.Itoa(i) + " is " + item.Type().String() +
+				", expected " + itemType.String())
+		}
+		newList.Index(i).Set(item)
 	}
 	f.Set(newList)
 
 	f = v.FieldByName("Metadata")
-	if f.IsValid() && f.CanSet() {
+	if f.IsValid() && f.CanSet() && this.metadata != nil &&
+		reflect.TypeOf(this.metadata).AssignableTo(f.Type()) {
 		f.Set(reflect.ValueOf(this.metadata))
 	}
 
 	return listItem, nil
 }
 
+// FromList is the reverse of AsList. It unpacks a "<Type>List" structure into
+// an elements container, taking the elements from its "List" field, keyed by
+// their index like New does for slices, and the metadata from its "Metadata"
+// field, if present.
+//
+// Returns an error if the list is not a pointer to a struct with a "List"
+// slice field.
+//
+// Example:
+//
+//	elems, err := object.FromList(testProtoList)
+func FromList(list interface{}) (ifs.IElements, error) {
+	if list == nil {
+		return nil, errors.New("list is nil")
+	}
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("list " + v.Type().String() + " is not a pointer to a struct")
+	}
+	v = v.Elem()
+	f := v.FieldByName("List")
+	if !f.IsValid() || f.Kind() != reflect.Slice {
+		return nil, errors.New(v.Type().Name() + " has no List field")
+	}
+	result := &Elements{}
+	result.elements = make([]*Element, 0, f.Len())
+	for i := 0; i < f.Len(); i++ {
+		item := f.Index(i)
+		if !item.CanInterface() {
+			return nil, errors.New(v.Type().Name() + " has an unexported List field")
+		}
+		result.Add(item.Interface(), i, nil)
+	}
+	m := v.FieldByName("Metadata")
+	if m.IsValid() && m.CanInterface() {
+		metadata, ok := m.Interface().(*l8api.L8MetaData)
+		if ok {
+			result.metadata = metadata
+		}
+	}
+	return result, nil
+}
+
 // Metadata returns the metadata associated with this elements collection.
 func (this *Elements) Metadata() *l8api.L8MetaData {
 	return this.metadata
@@ -426,8 +766,12 @@ func (this *Elements) Metadata() *l8api.L8MetaData {
 
 // IsFilterMode returns true if the container is operating in filter mode,
 // which means it has no query but contains elements that can be used as
-// filter criteria.
+// filter criteria. For a deserialized container the mode carried in the
+// payload is returned.
 func (this *Elements) IsFilterMode() bool {
+	if this.filterModeKnown {
+		return this.filterMode
+	}
 	if this.pquery == nil && (this.elements != nil || len(this.elements) == 1) {
 		return true
 	}
@@ -0,0 +1,174 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+)
+
+// Filter returns a new container with only the elements that match the
+// query, keeping their keys and errors. The "Total" metadata count is set
+// to the number of matching elements. Nil elements never match. A nil
+// query matches everything.
+//
+// This allows answering a query locally from a snapshot of elements,
+// without going back to the service that produced them.
+func (this *Elements) Filter(query ifs.IQuery) *Elements {
+	result := this.Clone(false)
+	if query != nil {
+		matched := make([]*Element, 0, len(this.elements))
+		for _, o := range this.elements {
+			if o != nil && o.element != nil && query.Match(o.element) {
+				matched = append(matched, o)
+			}
+		}
+		result.elements = matched
+		result.keyIndex = nil
+	}
+	result.metadata = withTotal(result.metadata, len(result.elements))
+	return result
+}
+
+// Apply is like Filter but also honours the sort, limit and page of the
+// query, in that order. Pages are zero based and only apply when a limit
+// is set, with the bounds of Page. The "Total" metadata count is the number of matching elements
+// before the page is cut, so callers can compute the number of pages.
+//
+// Elements are sorted by the value of the SortBy field, which may be a
+// dotted path and may start with the root type name. Field names match
+// case insensitively, ignoring underscores. Elements without the field
+// sort first.
+func (this *Elements) Apply(query ifs.IQuery) *Elements {
+	result := this.Filter(query)
+	if query == nil {
+		return result
+	}
+	if query.SortBy() != "" {
+		path := sortPath(query.SortBy(), query.RootType())
+		descending := query.Descending()
+		sort.SliceStable(result.elements, func(i, j int) bool {
+			a := fieldByPath(result.elements[i].element, path)
+			b := fieldByPath(result.elements[j].element, path)
+			if descending {
+				return compareValues(b, a) < 0
+			}
+			return compareValues(a, b) < 0
+		})
+	}
+	if query.Limit() > 0 {
+		start, end := pageBounds(int(query.Limit()), int(query.Page()), len(result.elements))
+		result.elements = result.elements[start:end]
+		result.keyIndex = nil
+	}
+	return result
+}
+
+// withTotal returns a copy of the metadata with the "Total" count set.
+func withTotal(metadata *l8api.L8MetaData, total int) *l8api.L8MetaData {
+	result := MergeMetadata(metadata, nil)
+	if result == nil {
+		result = &l8api.L8MetaData{}
+	}
+	if result.KeyCount == nil {
+		result.KeyCount = &l8api.L8Count{}
+	}
+	if result.KeyCount.Counts == nil {
+		result.KeyCount.Counts = make(map[string]float64)
+	}
+	result.KeyCount.Counts["Total"] = float64(total)
+	return result
+}
+
+// sortPath splits a sort field into its path, dropping a leading root type.
+func sortPath(sortBy, rootType string) []string {
+	path := strings.Split(sortBy, ".")
+	if len(path) > 1 && normalizeFieldName(path[0]) == normalizeFieldName(rootType) {
+		path = path[1:]
+	}
+	return path
+}
+
+// fieldByPath walks the struct fields of a value along a path and returns
+// the value at its end, or an invalid value if the path does not exist.
+func fieldByPath(any interface{}, path []string) reflect.Value {
+	v := reflect.ValueOf(any)
+	for _, name := range path {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}
+		}
+		name = normalizeFieldName(name)
+		v = v.FieldByNameFunc(func(n string) bool {
+			return normalizeFieldName(n) == name
+		})
+		if !v.IsValid() {
+			return v
+		}
+	}
+	return v
+}
+
+// compareValues orders two field values of the same kind, returning -1, 0
+// or 1. Invalid values sort first and values of other kinds are equal.
+func compareValues(a, b reflect.Value) int {
+	if !a.IsValid() || !b.IsValid() {
+		return boolOrder(a.IsValid()) - boolOrder(b.IsValid())
+	}
+	if a.Kind() != b.Kind() {
+		return 0
+	}
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return order(a.Int() < b.Int(), a.Int() > b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return order(a.Uint() < b.Uint(), a.Uint() > b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return order(a.Float() < b.Float(), a.Float() > b.Float())
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Bool:
+		return boolOrder(a.Bool()) - boolOrder(b.Bool())
+	}
+	return 0
+}
+
+// order converts the result of two comparisons to -1, 0 or 1.
+func order(less, greater bool) int {
+	if less {
+		return -1
+	}
+	if greater {
+		return 1
+	}
+	return 0
+}
+
+// boolOrder returns 1 for true and 0 for false.
+func boolOrder(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,176 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"strconv"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// ElementsAs returns the elements of the container as a typed slice.
+// Nil elements become the zero value of T.
+//
+// Returns an error, instead of panicking, if an element is not a T.
+//
+// Example:
+//
+//	protos, err := object.ElementsAs[*pb.MyMessage](elems)
+func ElementsAs[T any](elems ifs.IElements) ([]T, error) {
+	if elems == nil {
+		return nil, nil
+	}
+	values := elems.Elements()
+	result := make([]T, len(values))
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		typed, ok := value.(T)
+		if !ok {
+			return nil, typeMismatch[T]("element", i, value)
+		}
+		result[i] = typed
+	}
+	return result, nil
+}
+
+// MapAs returns the elements of the container as a typed map from their keys.
+// Nil elements become the zero value of V. If several elements share a key,
+// the last one wins.
+//
+// Returns an error, instead of panicking, if a key is not a K or an element
+// is not a V.
+//
+// Example:
+//
+//	byName, err := object.MapAs[string, *pb.MyMessage](elems)
+func MapAs[K comparable, V any](elems ifs.IElements) (map[K]V, error) {
+	if elems == nil {
+		return nil, nil
+	}
+	values := elems.Elements()
+	keys := elems.Keys()
+	result := make(map[K]V, len(values))
+	for i, value := range values {
+		key, ok := keys[i].(K)
+		if !ok {
+			return nil, typeMismatch[K]("key", i, keys[i])
+		}
+		if value == nil {
+			var zero V
+			result[key] = zero
+			continue
+		}
+		typed, ok := value.(V)
+		if !ok {
+			return nil, typeMismatch[V]("element", i, value)
+		}
+		result[key] = typed
+	}
+	return result, nil
+}
+
+// KeyValues returns an iterator over the (key, element) pairs of the container.
+//
+// Example:
+//
+//	for key, elem := range object.KeyValues(elems) {
+//	    ...
+//	}
+func KeyValues(elems ifs.IElements) iter.Seq2[interface{}, interface{}] {
+	return func(yield func(interface{}, interface{}) bool) {
+		if elems == nil {
+			return
+		}
+		values := elems.Elements()
+		keys := elems.Keys()
+		for i, value := range values {
+			if !yield(keys[i], value) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesErrors returns an iterator over the (element, error) pairs of the container.
+//
+// Example:
+//
+//	for elem, err := range object.ValuesErrors(elems) {
+//	    if err != nil {
+//	        ...
+//	    }
+//	}
+func ValuesErrors(elems ifs.IElements) iter.Seq2[interface{}, error] {
+	return func(yield func(interface{}, error) bool) {
+		if elems == nil {
+			return
+		}
+		values := elems.Elements()
+		errs := elems.Errors()
+		for i, value := range values {
+			if !yield(value, errs[i]) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesErrorsAs returns an iterator over the typed (element, error) pairs
+// of the container. Nil elements become the zero value of V. An element that
+// is not a V is yielded as the zero value of V with a type mismatch error.
+//
+// Example:
+//
+//	for msg, err := range object.ValuesErrorsAs[*pb.MyMessage](elems) {
+//	    if err != nil {
+//	        ...
+//	    }
+//	}
+func ValuesErrorsAs[V any](elems ifs.IElements) iter.Seq2[V, error] {
+	return func(yield func(V, error) bool) {
+		i := 0
+		for value, err := range ValuesErrors(elems) {
+			var typed V
+			if value != nil {
+				var ok bool
+				typed, ok = value.(V)
+				if !ok && err == nil {
+					err = typeMismatch[V]("element", i, value)
+				}
+			}
+			if !yield(typed, err) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// typeMismatch returns the error reported when a value is not of type T.
+func typeMismatch[T any](what string, index int, value interface{}) error {
+	return errors.New(what + " " + strconv.Itoa(index) + " is " + fmt.Sprintf("%T", value) +
+		", expected " + typeName[T]())
+}
+
+// typeName returns the name of type T, including interface types.
+func typeName[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
@@ -0,0 +1,155 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// Well known header keys for cross-cutting request context.
+const (
+	HeaderCorrelationID  = "correlation-id"  // string
+	HeaderTraceID        = "trace-id"        // string
+	HeaderSpanID         = "span-id"         // string
+	HeaderDeadline       = "deadline"        // int64, unix time in nanoseconds
+	HeaderTenantID       = "tenant-id"       // string
+	HeaderIdempotencyKey = "idempotency-key" // string
+	HeaderCaller         = "caller"          // string
+	HeaderContinuation   = "continuation"    // string, see NewContinuationToken
+	HeaderChunkID        = "chunk-id"        // string, see SerializeChunks
+	HeaderChunkSeq       = "chunk-seq"       // int32, zero based
+	HeaderChunkCount     = "chunk-count"     // int32
+)
+
+// SetHeader sets a header on the container. Values must be primitives:
+// string, bool, int, int32, int64, uint32, uint64, float32, float64 or byte.
+// Returns an error for any other type.
+func (this *Elements) SetHeader(key string, value interface{}) error {
+	if value == nil {
+		return errors.New("header " + key + " has no value")
+	}
+	if !isHeaderValue(value) {
+		return errors.New("header " + key + " has unsupported type " + reflect.TypeOf(value).String())
+	}
+	if this.headers == nil {
+		this.headers = make(map[string]interface{})
+	}
+	this.headers[key] = value
+	return nil
+}
+
+// Header returns the value of a header and whether it is set.
+func (this *Elements) Header(key string) (interface{}, bool) {
+	value, ok := this.headers[key]
+	return value, ok
+}
+
+// HeaderString returns the value of a string header, or an empty string if
+// it is not set or not a string.
+func (this *Elements) HeaderString(key string) string {
+	value, _ := this.headers[key].(string)
+	return value
+}
+
+// HeaderInt64 returns the value of an int64 header, or 0 if it is not set
+// or not an int64.
+func (this *Elements) HeaderInt64(key string) int64 {
+	value, _ := this.headers[key].(int64)
+	return value
+}
+
+// RemoveHeader removes a header from the container.
+func (this *Elements) RemoveHeader(key string) {
+	delete(this.headers, key)
+}
+
+// Headers returns a copy of all the headers of the container.
+func (this *Elements) Headers() map[string]interface{} {
+	return copyHeaders(this.headers)
+}
+
+// isHeaderValue returns true if the value is a supported header type.
+func isHeaderValue(value interface{}) bool {
+	switch value.(type) {
+	case string, bool, int, int32, int64, uint32, uint64, float32, float64, byte:
+		return true
+	}
+	return false
+}
+
+// copyHeaders returns a copy of the headers map, or nil if it is empty.
+func copyHeaders(headers map[string]interface{}) map[string]interface{} {
+	if len(headers) == 0 {
+		return nil
+	}
+	result := make(map[string]interface{}, len(headers))
+	for key, value := range headers {
+		result[key] = value
+	}
+	return result
+}
+
+// addHeaders writes the headers section: the header count (int) followed by
+// each key (string) and typed value, in key order.
+func (this *Elements) addHeaders(obj *Object) error {
+	keys := make([]string, 0, len(this.headers))
+	for key := range this.headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	obj.Add(len(keys))
+	for _, key := range keys {
+		obj.Add(key)
+		err := obj.Add(this.headers[key])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getHeaders reads the headers section written by addHeaders.
+func (this *Elements) getHeaders(obj *Object) error {
+	s, err := obj.Get()
+	if err != nil {
+		return err
+	}
+	size, ok := s.(int)
+	if !ok {
+		return errors.New("invalid elements headers section")
+	}
+	this.headers = nil
+	for i := 0; i < size; i++ {
+		key, err := obj.Get()
+		if err != nil {
+			return err
+		}
+		value, err := obj.Get()
+		if err != nil {
+			return err
+		}
+		k, ok := key.(string)
+		if !ok {
+			return errors.New("invalid elements header key")
+		}
+		if this.headers == nil {
+			this.headers = make(map[string]interface{}, size)
+		}
+		this.headers[k] = value
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// kindJoinPair is the type prefix of a serialized JoinPair.
+const kindJoinPair reflect.Kind = 73
+
+// JoinMode selects which elements a join keeps.
+type JoinMode int
+
+const (
+	// JoinInner keeps a pair for every matching left and right element.
+	JoinInner JoinMode = iota
+	// JoinLeft is like JoinInner, but also keeps the left elements without a
+	// match, paired with a nil right element.
+	JoinLeft
+	// JoinAnti keeps only the left elements without a match, as they are.
+	JoinAnti
+)
+
+// JoinPair is a left element and the right element it was matched with.
+// Pairs are serialized with their elements, so join results can be sent
+// like any other container.
+type JoinPair struct {
+	Left  interface{}
+	Right interface{}
+}
+
+// JoinMerge combines a matched left and right element into a single value.
+// The right element is nil for unmatched elements of a left join.
+type JoinMerge func(left, right interface{}) interface{}
+
+// Join matches the elements of this container, the left side, with the
+// elements of another container, the right side, and returns the result as
+// *JoinPair elements. See JoinWith.
+//
+// Example, joining devices with their stats by the device id:
+//
+//	joined := devices.Join(stats, "id", "deviceid", object.JoinLeft)
+func (this *Elements) Join(other ifs.IElements, leftPath, rightPath string, mode JoinMode) *Elements {
+	return this.JoinWith(other, leftPath, rightPath, mode, nil)
+}
+
+// JoinWith matches the elements of this container with the elements of
+// another container and returns a container of the matches, keyed with the
+// left keys. Each side is matched on a property path of its elements, or on
+// the element keys when the path is empty. Elements with no value for the
+// path never match.
+//
+// With a merge function, the result holds the merged values, otherwise
+// *JoinPair elements. An anti join always holds the left elements. Errors of
+// both the left and right element are carried to the result.
+func (this *Elements) JoinWith(other ifs.IElements, leftPath, rightPath string, mode JoinMode, merge JoinMerge) *Elements {
+	result := &Elements{headers: copyHeaders(this.headers)}
+	index := newJoinIndex(other, rightPath)
+	for _, o := range this.elements {
+		if o == nil {
+			continue
+		}
+		matches := index.lookup(joinValue(o.element, o.key, leftPath))
+		if mode == JoinAnti {
+			if len(matches) == 0 {
+				result.Add(o.element, o.key, o.error)
+			}
+			continue
+		}
+		if len(matches) == 0 && mode == JoinLeft {
+			result.Add(joined(o.element, nil, merge), o.key, o.error)
+			continue
+		}
+		for _, i := range matches {
+			result.Add(joined(o.element, index.elements[i], merge), o.key, joinErrors(o.error, index.errors[i]))
+		}
+	}
+	result.metadata = withTotal(nil, len(result.elements))
+	return result
+}
+
+// joined returns the pair, or the merged value, of a left and right element.
+func joined(left, right interface{}, merge JoinMerge) interface{} {
+	if merge != nil {
+		return merge(left, right)
+	}
+	return &JoinPair{Left: left, Right: right}
+}
+
+// joinErrors returns the error of a matched pair: the error of the side that
+// has one, as is, or both errors joined.
+func joinErrors(left, right error) error {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	return errors.Join(left, right)
+}
+
+// addJoinPair serializes a pair as its left and right elements.
+func addJoinPair(pair *JoinPair, obj *Object) error {
+	err := obj.Add(pair.Left)
+	if err != nil {
+		return err
+	}
+	return obj.Add(pair.Right)
+}
+
+// getJoinPair deserializes a pair written by addJoinPair.
+func getJoinPair(data *[]byte, location *int, registry ifs.IRegistry) (*JoinPair, error) {
+	obj := newDecode(data, location, registry)
+	left, err := obj.Get()
+	if err != nil {
+		return nil, err
+	}
+	right, err := obj.Get()
+	if err != nil {
+		return nil, err
+	}
+	return &JoinPair{Left: left, Right: right}, nil
+}
+
+// joinIndex indexes the right side of a join by its join values.
+type joinIndex struct {
+	elements []interface{}
+	errors   []error
+	values   []interface{}
+	hashed   map[interface{}][]int
+}
+
+// newJoinIndex indexes the elements of a container by their join values.
+func newJoinIndex(elems ifs.IElements, path string) *joinIndex {
+	index := &joinIndex{hashed: make(map[interface{}][]int)}
+	if elems == nil {
+		return index
+	}
+	index.elements = elems.Elements()
+	index.errors = elems.Errors()
+	keys := elems.Keys()
+	index.values = make([]interface{}, len(index.elements))
+	for i, elem := range index.elements {
+		value := joinValue(elem, keys[i], path)
+		index.values[i] = value
+		if hashableKey(value) {
+			index.hashed[value] = append(index.hashed[value], i)
+		}
+	}
+	return index
+}
+
+// lookup returns the indexes of the elements with the given join value.
+// Values that cannot be hashed are compared one by one.
+func (this *joinIndex) lookup(value interface{}) []int {
+	if value == nil {
+		return nil
+	}
+	if hashableKey(value) {
+		return this.hashed[value]
+	}
+	var result []int
+	for i, v := range this.values {
+		if v != nil && keysEqual(v, value) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// joinValue returns the key of an element if the path is empty, otherwise
+// the value of the path, or nil if the element has no value for it.
+func joinValue(elem, key interface{}, path string) interface{} {
+	if path == "" {
+		return key
+	}
+	if elem == nil {
+		return nil
+	}
+	v := propertyValue(elem, path)
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
@@ -0,0 +1,121 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import "reflect"
+
+// Len returns the number of elements in the container.
+func (this *Elements) Len() int {
+	return len(this.elements)
+}
+
+// ByKey returns the element with the given key and true, or nil and false if
+// no element has the key. If several elements share a key, the first one is
+// returned. Lookups use an index that is built on first use.
+func (this *Elements) ByKey(key interface{}) (interface{}, bool) {
+	index := this.indexOfKey(key)
+	if index == -1 {
+		return nil, false
+	}
+	return this.elements[index].element, true
+}
+
+// ErrorByKey returns the error of the element with the given key, or nil.
+func (this *Elements) ErrorByKey(key interface{}) error {
+	index := this.indexOfKey(key)
+	if index == -1 {
+		return nil
+	}
+	return this.elements[index].error
+}
+
+// HasKey returns true if an element has the given key.
+func (this *Elements) HasKey(key interface{}) bool {
+	return this.indexOfKey(key) != -1
+}
+
+// RemoveKey removes all the elements with the given key and returns true if
+// any was removed.
+func (this *Elements) RemoveKey(key interface{}) bool {
+	if !this.HasKey(key) {
+		return false
+	}
+	kept := make([]*Element, 0, len(this.elements))
+	for _, o := range this.elements {
+		if o != nil && keysEqual(o.key, key) {
+			continue
+		}
+		kept = append(kept, o)
+	}
+	this.elements = kept
+	this.keyIndex = nil
+	return true
+}
+
+// indexOfKey returns the index of the first element with the given key, or -1.
+// Hashable keys are looked up in the index, others with a linear scan.
+func (this *Elements) indexOfKey(key interface{}) int {
+	if !hashableKey(key) {
+		for i, o := range this.elements {
+			if o != nil && keysEqual(o.key, key) {
+				return i
+			}
+		}
+		return -1
+	}
+	if this.keyIndex == nil {
+		this.buildKeyIndex()
+	}
+	index, ok := this.keyIndex[key]
+	if !ok {
+		return -1
+	}
+	return index
+}
+
+// buildKeyIndex indexes the first element of every hashable key.
+func (this *Elements) buildKeyIndex() {
+	this.keyIndex = make(map[interface{}]int, len(this.elements))
+	for i, o := range this.elements {
+		this.indexKey(o, i)
+	}
+}
+
+// indexKey adds an element to the index if the index is built, the key is
+// hashable and not already indexed.
+func (this *Elements) indexKey(o *Element, i int) {
+	if this.keyIndex == nil || o == nil || !hashableKey(o.key) {
+		return
+	}
+	if _, ok := this.keyIndex[o.key]; !ok {
+		this.keyIndex[o.key] = i
+	}
+}
+
+// hashableKey returns true if the key can be used as a map key.
+func hashableKey(key interface{}) bool {
+	return key != nil && reflect.TypeOf(key).Comparable()
+}
+
+// keysEqual compares two keys, returning false instead of panicking for
+// keys that are not comparable.
+func keysEqual(a, b interface{}) (equal bool) {
+	defer func() {
+		if recover() != nil {
+			equal = false
+		}
+	}()
+	return a == b
+}
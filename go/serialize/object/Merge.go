@@ -0,0 +1,119 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"strings"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+	"google.golang.org/protobuf/proto"
+)
+
+// MergeMode selects how Merge combines the elements of two containers.
+type MergeMode int
+
+const (
+	// MergeAppend appends all the elements, keeping their keys and errors.
+	MergeAppend MergeMode = iota
+	// MergeUpsert replaces elements that have the same key and appends the
+	// rest. Elements without a key are always appended.
+	MergeUpsert
+)
+
+// Merge adds the elements of another container to this one with their keys
+// and errors intact, and combines the metadata counts of both with
+// MergeMetadata. The "Total" count is set to the number of merged elements.
+// Unlike Append, nothing but the values is lost. This is typically used to
+// gather partial results from several shards into a single response.
+func (this *Elements) Merge(elements ifs.IElements, mode MergeMode) {
+	if elements == nil {
+		return
+	}
+	values := elements.Elements()
+	keys := elements.Keys()
+	errs := elements.Errors()
+	for i, value := range values {
+		if mode == MergeUpsert && keys[i] != nil {
+			index := this.indexOfKey(keys[i])
+			if index != -1 {
+				this.elements[index] = &Element{element: value, key: keys[i], error: errs[i]}
+				continue
+			}
+		}
+		this.Add(value, keys[i], errs[i])
+	}
+	this.metadata = withTotal(MergeMetadata(this.metadata, elements.Metadata()), len(this.elements))
+}
+
+// MergeMetadata combines two metadata instances into a new one. Counts
+// present in only one of them are copied. Counts present in both are
+// combined by meaning: "Version" and max aggregations keep the highest
+// value, min aggregations the lowest, paging counts and averages keep the
+// value of a, which cannot be combined, and other counts, including
+// "Total", group counts and count and sum aggregations, are summed. Callers
+// that hold the merged elements should set "Total" from their number.
+// Returns nil if both are nil. The inputs are not modified.
+func MergeMetadata(a, b *l8api.L8MetaData) *l8api.L8MetaData {
+	if a == nil && b == nil {
+		return nil
+	}
+	if a == nil {
+		return proto.Clone(b).(*l8api.L8MetaData)
+	}
+	result := proto.Clone(a).(*l8api.L8MetaData)
+	if b == nil || b.KeyCount == nil {
+		return result
+	}
+	if result.KeyCount == nil {
+		result.KeyCount = &l8api.L8Count{}
+	}
+	if result.KeyCount.Counts == nil {
+		result.KeyCount.Counts = make(map[string]float64)
+	}
+	counts := result.KeyCount.Counts
+	for key, count := range b.KeyCount.Counts {
+		current, ok := counts[key]
+		if !ok {
+			counts[key] = count
+			continue
+		}
+		counts[key] = mergeCount(key, current, count)
+	}
+	return result
+}
+
+// mergeCount combines the values of a count present in both metadata.
+func mergeCount(key string, a, b float64) float64 {
+	// Aggregations are named "<group key>/<func>(<field>)", see AddAggregates
+	name := key[strings.LastIndex(key, "/")+1:]
+	switch {
+	case key == CountPage || key == CountPageSize || key == CountOffset || key == CountHasMore:
+		return a
+	case key == CountVersion || strings.HasPrefix(name, AggregateMax.String()+"("):
+		if b > a {
+			return b
+		}
+		return a
+	case strings.HasPrefix(name, AggregateMin.String()+"("):
+		if b < a {
+			return b
+		}
+		return a
+	case strings.HasPrefix(name, AggregateAvg.String()+"("):
+		return a
+	}
+	return a + b
+}
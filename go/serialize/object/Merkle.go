@@ -0,0 +1,327 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/saichler/l8types/go/ifs"
+	"google.golang.org/protobuf/proto"
+)
+
+// kindMerkle is the type prefix of a serialized Merkle tree.
+const kindMerkle reflect.Kind = 72
+
+// MaxMerkleDepth is the largest supported Merkle tree depth, giving
+// 2^MaxMerkleDepth key ranges. It bounds a serialized tree to 2MB.
+const MaxMerkleDepth = 16
+
+// KeyRange is a range of key hashes, inclusive at both ends. Keys are placed
+// in ranges by MerkleKeyHash.
+type KeyRange struct {
+	Start uint64
+	End   uint64
+}
+
+// Contains returns true if the hash of the key is in the range.
+func (this KeyRange) Contains(key interface{}) bool {
+	hash := MerkleKeyHash(key)
+	return hash >= this.Start && hash <= this.End
+}
+
+// MerkleTree summarizes the contents of a container, so two replicas can find
+// the keys they disagree on by exchanging hashes instead of elements. The key
+// hash space is split into 2^depth equal ranges, the leaves, and every node
+// hashes its two children. Trees of the same depth built from equal
+// contents have equal hashes, whatever the order of the elements.
+type MerkleTree struct {
+	depth  int
+	levels [][][]byte // levels[l][i] is the hash of node i at level l, 0 is the root
+}
+
+// NewMerkleTree builds a tree of the given depth over the elements of a
+// container. Each element is hashed over its key and its deterministic
+// serialized value, protobuf values with deterministic proto encoding and
+// other values with this package's encoding, and its error message.
+func NewMerkleTree(elems ifs.IElements, depth int) (*MerkleTree, error) {
+	if depth < 0 || depth > MaxMerkleDepth {
+		return nil, errors.New("invalid Merkle tree depth " + strconv.Itoa(depth))
+	}
+	type leaf struct {
+		keyHash uint64
+		hash    []byte
+	}
+	buckets := make([][]leaf, 1<<depth)
+	if elems != nil {
+		values := elems.Elements()
+		errs := elems.Errors()
+		for i, key := range elems.Keys() {
+			hash, err := merkleElementHash(key, values[i], errs[i])
+			if err != nil {
+				return nil, err
+			}
+			keyHash := MerkleKeyHash(key)
+			bucket := 0
+			if depth > 0 {
+				bucket = int(keyHash >> (64 - depth))
+			}
+			buckets[bucket] = append(buckets[bucket], leaf{keyHash: keyHash, hash: hash})
+		}
+	}
+
+	tree := &MerkleTree{depth: depth, levels: make([][][]byte, depth+1)}
+	tree.levels[depth] = make([][]byte, len(buckets))
+	for i, bucket := range buckets {
+		sort.Slice(bucket, func(a, b int) bool {
+			if bucket[a].keyHash != bucket[b].keyHash {
+				return bucket[a].keyHash < bucket[b].keyHash
+			}
+			return bytes.Compare(bucket[a].hash, bucket[b].hash) < 0
+		})
+		h := sha256.New()
+		for _, l := range bucket {
+			h.Write(l.hash)
+		}
+		tree.levels[depth][i] = h.Sum(nil)
+	}
+	tree.hashLevels()
+	return tree, nil
+}
+
+// hashLevels computes the levels above the leaves, each node hashing its
+// two children.
+func (this *MerkleTree) hashLevels() {
+	for level := this.depth - 1; level >= 0; level-- {
+		this.levels[level] = make([][]byte, 1<<level)
+		for i := range this.levels[level] {
+			h := sha256.New()
+			h.Write(this.levels[level+1][2*i])
+			h.Write(this.levels[level+1][2*i+1])
+			this.levels[level][i] = h.Sum(nil)
+		}
+	}
+}
+
+// MerkleKeyHash returns the position of a key in the key hash space: the
+// first 8 bytes of the SHA-256 digest of the key, formatted like HashKey.
+func MerkleKeyHash(key interface{}) uint64 {
+	s, ok := key.(string)
+	if !ok {
+		s = fmt.Sprint(key)
+	}
+	digest := sha256.Sum256([]byte(s))
+	return binary.BigEndian.Uint64(digest[:8])
+}
+
+// merkleElementHash returns the hash of an element.
+func merkleElementHash(key, value interface{}, err error) ([]byte, error) {
+	h := sha256.New()
+	keyData, e := merkleValueData(key)
+	if e != nil {
+		return nil, e
+	}
+	valueData, e := merkleValueData(value)
+	if e != nil {
+		return nil, e
+	}
+	for _, data := range [][]byte{keyData, valueData} {
+		binary.Write(h, binary.BigEndian, int64(len(data)))
+		h.Write(data)
+	}
+	if err != nil {
+		h.Write([]byte(err.Error()))
+	}
+	return h.Sum(nil), nil
+}
+
+// merkleValueData returns the deterministic encoding of a value. Protobuf
+// messages use deterministic proto encoding, maps are encoded with their
+// entries sorted and slices item by item, so map entries and proto maps
+// nested in them are encoded in a stable order. Other values use this
+// package's encoding.
+func merkleValueData(value interface{}) ([]byte, error) {
+	pb, ok := value.(proto.Message)
+	if ok && !reflect.ValueOf(value).IsNil() {
+		return proto.MarshalOptions{Deterministic: true}.Marshal(pb)
+	}
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Kind() == reflect.Map && !v.IsNil():
+		entries := make([][]byte, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keyData, err := merkleValueData(key.Interface())
+			if err != nil {
+				return nil, err
+			}
+			valueData, err := merkleValueData(v.MapIndex(key).Interface())
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, merkleJoin(keyData, valueData))
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i], entries[j]) < 0
+		})
+		return merkleJoin(append([][]byte{[]byte(v.Type().String())}, entries...)...), nil
+	case v.Kind() == reflect.Slice && !v.IsNil() && v.Type().Elem().Kind() != reflect.Uint8:
+		items := [][]byte{[]byte(v.Type().String())}
+		for i := 0; i < v.Len(); i++ {
+			itemData, err := merkleValueData(v.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, itemData)
+		}
+		return merkleJoin(items...), nil
+	}
+	obj := NewEncode()
+	err := obj.Add(value)
+	if err != nil {
+		return nil, err
+	}
+	return obj.Data(), nil
+}
+
+// merkleJoin concatenates encodings, each prefixed with its size.
+func merkleJoin(parts ...[]byte) []byte {
+	var result []byte
+	for _, part := range parts {
+		result = binary.BigEndian.AppendUint64(result, uint64(len(part)))
+		result = append(result, part...)
+	}
+	return result
+}
+
+// Depth returns the depth of the tree. The leaves are at this level.
+func (this *MerkleTree) Depth() int {
+	return this.depth
+}
+
+// Root returns the hash of the whole tree.
+func (this *MerkleTree) Root() []byte {
+	return this.levels[0][0]
+}
+
+// Hash returns the hash of node index at level, which covers Range(level,
+// index), or nil if there is no such node.
+func (this *MerkleTree) Hash(level, index int) []byte {
+	if level < 0 || level > this.depth || index < 0 || index >= len(this.levels[level]) {
+		return nil
+	}
+	return this.levels[level][index]
+}
+
+// Range returns the key hash range covered by node index at level.
+func (this *MerkleTree) Range(level, index int) KeyRange {
+	start := uint64(index) << (64 - level)
+	return KeyRange{Start: start, End: start | ^uint64(0)>>level}
+}
+
+// Compare returns the key ranges in which the two trees differ, in order and
+// with adjacent ranges joined. Only subtrees with different hashes are
+// visited. Returns an error if the trees do not have the same depth.
+func (this *MerkleTree) Compare(other *MerkleTree) ([]KeyRange, error) {
+	if other == nil || other.depth != this.depth {
+		return nil, errors.New("Merkle trees have different depths")
+	}
+	var result []KeyRange
+	var visit func(level, index int)
+	visit = func(level, index int) {
+		if bytes.Equal(this.levels[level][index], other.levels[level][index]) {
+			return
+		}
+		if level < this.depth {
+			visit(level+1, 2*index)
+			visit(level+1, 2*index+1)
+			return
+		}
+		r := this.Range(level, index)
+		if len(result) > 0 && result[len(result)-1].End+1 == r.Start {
+			result[len(result)-1].End = r.End
+			return
+		}
+		result = append(result, r)
+	}
+	visit(0, 0)
+	return result, nil
+}
+
+// KeysInRanges returns a container with the elements of elems whose keys are
+// in one of the ranges, with their keys and errors. This is what a replica
+// sends to repair another after comparing their trees.
+func KeysInRanges(elems ifs.IElements, ranges []KeyRange) *Elements {
+	result := &Elements{}
+	if elems == nil {
+		return result
+	}
+	values := elems.Elements()
+	errs := elems.Errors()
+	for i, key := range elems.Keys() {
+		for _, r := range ranges {
+			if r.Contains(key) {
+				result.Add(values[i], key, errs[i])
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Serialize converts the tree to a byte slice.
+// Format: the kindMerkle prefix (int32), the depth (int32) and the 32 byte
+// hash of every leaf, in order. The other levels are rebuilt by Deserialize.
+func (this *MerkleTree) Serialize() ([]byte, error) {
+	leaves := this.levels[this.depth]
+	data := make([]byte, 8+len(leaves)*sha256.Size)
+	location := 0
+	addInt32(int32(kindMerkle), &data, &location)
+	addInt32(int32(this.depth), &data, &location)
+	for _, hash := range leaves {
+		copy(data[location:], hash)
+		location += sha256.Size
+	}
+	return data, nil
+}
+
+// Deserialize reconstructs the tree from a byte slice written by Serialize.
+func (this *MerkleTree) Deserialize(data []byte) error {
+	if len(data) < 8 {
+		return errors.New("invalid Merkle tree payload")
+	}
+	location := 0
+	if reflect.Kind(getInt32(&data, &location)) != kindMerkle {
+		return errors.New("payload is not a Merkle tree")
+	}
+	depth := int(getInt32(&data, &location))
+	if depth < 0 || depth > MaxMerkleDepth || len(data) != 8+(1<<depth)*sha256.Size {
+		return errors.New("invalid Merkle tree payload")
+	}
+	this.depth = depth
+	this.levels = make([][][]byte, depth+1)
+	this.levels[depth] = make([][]byte, 1<<depth)
+	for i := range this.levels[depth] {
+		this.levels[depth][i] = append([]byte(nil), data[location:location+sha256.Size]...)
+		location += sha256.Size
+	}
+	this.hashLevels()
+	return nil
+}
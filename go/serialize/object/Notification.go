@@ -0,0 +1,264 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// maxTrackedGap bounds the number of missing sequence numbers remembered
+// for a single gap, so a corrupted sequence number cannot exhaust memory.
+const maxTrackedGap = 4096
+
+// maxTrackedMissing bounds the number of missing sequence numbers remembered
+// for a source across all its gaps. The oldest are forgotten first.
+const maxTrackedMissing = 4 * maxTrackedGap
+
+// AddChange adds an element to a notification with the change it reports:
+// ChangeAdded, ChangeChanged or ChangeRemoved.
+func (this *Elements) AddChange(elem interface{}, key interface{}, change ChangeType) {
+	this.Add(elem, key, nil)
+	this.elements[len(this.elements)-1].change = change
+}
+
+// ChangeTypes returns the change type of each element, 0 for elements
+// added without one.
+func (this *Elements) ChangeTypes() []ChangeType {
+	result := make([]ChangeType, len(this.elements))
+	for i, o := range this.elements {
+		if o != nil {
+			result[i] = o.change
+		}
+	}
+	return result
+}
+
+// SetSequence marks the container as a notification with the given source
+// ID and sequence number. Sequence numbers start at 1 and increase by one
+// for every notification of the source, see Sequencer.
+func (this *Elements) SetSequence(source string, sequence uint64) {
+	this.notification = true
+	this.source = source
+	this.sequence = sequence
+}
+
+// Source returns the ID of the source of a sequenced notification.
+func (this *Elements) Source() string {
+	return this.source
+}
+
+// Sequence returns the sequence number of a notification, or 0 if it is
+// not sequenced.
+func (this *Elements) Sequence() uint64 {
+	return this.sequence
+}
+
+// addNotification writes the notification section: the source (string), the
+// sequence (uint64) and the element change count (int) followed by the
+// change type of each element (int32). The count is 0 if no element has a
+// change type.
+func (this *Elements) addNotification(obj *Object) {
+	obj.Add(this.source)
+	obj.Add(this.sequence)
+	changes := 0
+	for _, o := range this.elements {
+		if o != nil && o.change != 0 {
+			changes = len(this.elements)
+			break
+		}
+	}
+	obj.Add(changes)
+	for i := 0; i < changes; i++ {
+		var change ChangeType
+		if this.elements[i] != nil {
+			change = this.elements[i].change
+		}
+		obj.Add(int32(change))
+	}
+}
+
+// getNotification reads the notification section written by addNotification.
+func (this *Elements) getNotification(obj *Object) error {
+	values := make([]interface{}, 3)
+	for i := range values {
+		value, err := obj.Get()
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+	source, ok1 := values[0].(string)
+	sequence, ok2 := values[1].(uint64)
+	changes, ok3 := values[2].(int)
+	if !ok1 || !ok2 || !ok3 || (changes != 0 && changes != len(this.elements)) {
+		return errors.New("invalid elements notification section")
+	}
+	this.source = source
+	this.sequence = sequence
+	for i := 0; i < changes; i++ {
+		c, err := obj.Get()
+		if err != nil {
+			return err
+		}
+		change, _ := c.(int32)
+		if this.elements[i] != nil {
+			this.elements[i].change = ChangeType(change)
+		}
+	}
+	return nil
+}
+
+// Sequencer stamps the notifications of a source with increasing sequence
+// numbers, starting at 1. It is safe for concurrent use.
+type Sequencer struct {
+	source   string
+	mtx      *sync.Mutex
+	sequence uint64
+}
+
+// NewSequencer creates a sequencer for the given source ID.
+func NewSequencer(source string) *Sequencer {
+	return &Sequencer{source: source, mtx: &sync.Mutex{}}
+}
+
+// Stamp sets the source and the next sequence number on a notification and
+// returns the sequence number.
+func (this *Sequencer) Stamp(elems *Elements) uint64 {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.sequence++
+	elems.SetSequence(this.source, this.sequence)
+	return this.sequence
+}
+
+// SequenceStatus tells how a notification relates to the ones received
+// before it from the same source.
+type SequenceStatus int
+
+const (
+	SequenceUnsequenced SequenceStatus = iota // The notification has no sequence number
+	SequenceInOrder                           // The notification is the next one expected
+	SequenceGap                               // Notifications before this one are missing
+	SequenceLate                              // The notification fills an earlier gap
+	SequenceDuplicate                         // The notification was already received
+)
+
+// SequenceTracker detects gaps, late arrivals and duplicates in the
+// notifications received from every source. The first notification of a
+// source is in order, whatever its sequence number. It is safe for
+// concurrent use.
+type SequenceTracker struct {
+	mtx     *sync.Mutex
+	sources map[string]*sourceState
+}
+
+// sourceState holds what was received from one source.
+type sourceState struct {
+	highest uint64
+	missing map[uint64]bool
+}
+
+// NewSequenceTracker creates an empty tracker.
+func NewSequenceTracker() *SequenceTracker {
+	return &SequenceTracker{mtx: &sync.Mutex{}, sources: make(map[string]*sourceState)}
+}
+
+// TrackElements tracks the source and sequence number of a notification.
+func (this *SequenceTracker) TrackElements(elems *Elements) SequenceStatus {
+	return this.Track(elems.source, elems.sequence)
+}
+
+// Track records a sequence number received from a source and returns its
+// status. Gaps are remembered until the missing notifications arrive late,
+// up to 16384 missing sequence numbers per source, the oldest being
+// forgotten first.
+func (this *SequenceTracker) Track(source string, sequence uint64) SequenceStatus {
+	if sequence == 0 {
+		return SequenceUnsequenced
+	}
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	state, ok := this.sources[source]
+	if !ok {
+		this.sources[source] = &sourceState{highest: sequence, missing: make(map[uint64]bool)}
+		return SequenceInOrder
+	}
+	switch {
+	case sequence == state.highest+1:
+		state.highest = sequence
+		return SequenceInOrder
+	case sequence > state.highest:
+		from := state.highest + 1
+		if sequence-from > maxTrackedGap {
+			from = sequence - maxTrackedGap
+		}
+		for missing := from; missing < sequence; missing++ {
+			state.missing[missing] = true
+		}
+		state.highest = sequence
+		state.forgetOldest()
+		return SequenceGap
+	case state.missing[sequence]:
+		delete(state.missing, sequence)
+		return SequenceLate
+	}
+	return SequenceDuplicate
+}
+
+// forgetOldest drops the lowest missing sequence numbers beyond
+// maxTrackedMissing, so a source with many gaps cannot exhaust memory.
+func (this *sourceState) forgetOldest() {
+	if len(this.missing) <= maxTrackedMissing {
+		return
+	}
+	sequences := make([]uint64, 0, len(this.missing))
+	for sequence := range this.missing {
+		sequences = append(sequences, sequence)
+	}
+	sort.Slice(sequences, func(i, j int) bool {
+		return sequences[i] < sequences[j]
+	})
+	for _, sequence := range sequences[:len(sequences)-maxTrackedMissing] {
+		delete(this.missing, sequence)
+	}
+}
+
+// Missing returns the sequence numbers of a source that were skipped and
+// did not arrive yet, in order.
+func (this *SequenceTracker) Missing(source string) []uint64 {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	state, ok := this.sources[source]
+	if !ok {
+		return nil
+	}
+	result := make([]uint64, 0, len(state.missing))
+	for sequence := range state.missing {
+		result = append(result, sequence)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] < result[j]
+	})
+	return result
+}
+
+// Reset forgets a source, e.g. after a full resynchronization.
+func (this *SequenceTracker) Reset(source string) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	delete(this.sources, source)
+}
@@ -46,9 +46,10 @@ import (
 // Object uses exponential buffer growth to minimize memory allocations during
 // serialization of large or numerous objects.
 type Object struct {
-	data     *[]byte       // Internal byte buffer for serialized data
-	location *int          // Current read/write position in the buffer
-	registry ifs.IRegistry // Type registry for deserializing complex types
+	data      *[]byte          // Internal byte buffer for serialized data
+	location  *int             // Current read/write position in the buffer
+	registry  ifs.IRegistry    // Type registry for deserializing complex types
+	redaction *RedactionPolicy // Optional policy applied to values before encoding
 }
 
 // Primitive defines the interface for serializing/deserializing primitive types.
@@ -114,6 +115,13 @@ func (this *Object) Location() int {
 	return *this.location
 }
 
+// SetRedaction sets a redaction policy that is applied to every value added
+// from now on. The added values are not mutated, a redacted copy is encoded.
+// A nil policy disables redaction.
+func (this *Object) SetRedaction(policy *RedactionPolicy) {
+	this.redaction = policy
+}
+
 // Add serializes the given value and appends it to the internal buffer.
 // It automatically detects the type of the value and uses the appropriate
 // serialization strategy. The type information is prefixed to enable
@@ -122,10 +130,16 @@ func (this *Object) Location() int {
 // Supported types:
 //   - Primitives: int, int32, int64, uint32, uint64, float32, float64, string, bool, byte
 //   - Complex: slices, maps, pointers to structs (Protocol Buffers)
+//   - Errors: any error, decoded as an *ElementError
+//   - Changes: *Change, as produced by Diff
 //
 // Returns an error if the type is not supported.
 func (this *Object) Add(any interface{}) error {
 
+	if this.redaction != nil {
+		any = this.redaction.Apply(any)
+	}
+
 	switch v := any.(type) {
 	case int:
 		this.addKind(reflect.Int)
@@ -167,6 +181,29 @@ func (this *Object) Add(any interface{}) error {
 		this.addKind(reflect.Uint8)
 		addByte(v, this.data, this.location)
 		return nil
+	case error:
+		ee := ErrorOf(v)
+		if ee == nil {
+			this.addKind(reflect.Ptr)
+			return addStruct(nil, this.data, this.location)
+		}
+		this.addKind(kindError)
+		addElementError(ee, this.data, this.location)
+		return nil
+	case *Change:
+		if v == nil {
+			this.addKind(reflect.Ptr)
+			return addStruct(nil, this.data, this.location)
+		}
+		this.addKind(kindChange)
+		return addChange(v, this)
+	case *JoinPair:
+		if v == nil {
+			this.addKind(reflect.Ptr)
+			return addStruct(nil, this.data, this.location)
+		}
+		this.addKind(kindJoinPair)
+		return addJoinPair(v, this)
 	case types.Slice:
 		this.addKind(reflect.Slice)
 		return addSlice(v, this.data, this.location)
@@ -246,6 +283,20 @@ func (this *Object) Get() (interface{}, error) {
 		fallthrough
 	case reflect.Ptr:
 		return getStruct(this.data, this.location, this.registry)
+	case kindColumnar:
+		return getColumnar(this.data, this.location, this.registry)
+	case kindTimeSeries:
+		return getTimeSeries(this.data, this.location)
+	case kindCompressed:
+		return getCompressedObject(this.data, this.location, this.registry)
+	case kindChecksum:
+		return getChecksumObject(this.data, this.location, this.registry)
+	case kindError:
+		return getElementError(this.data, this.location), nil
+	case kindChange:
+		return getChange(this.data, this.location, this.registry)
+	case kindJoinPair:
+		return getJoinPair(this.data, this.location, this.registry)
 	}
 	return nil, errors.New("Did not find any Object for kind " + kind.String())
 }
@@ -310,11 +361,18 @@ func DataOf(elem interface{}) ([]byte, error) {
 //   - data: The serialized byte slice
 //   - r: Type registry for resolving complex types
 //
+// The payload is verified and decompressed with Unframe, so it is rejected
+// if it has no checksum while checksums are required.
+//
 // Returns nil, nil if data is nil.
 func ElemOf(data []byte, r ifs.IRegistry) (interface{}, error) {
 	if data == nil {
 		return nil, nil
 	}
+	data, err := Unframe(data)
+	if err != nil {
+		return nil, err
+	}
 	location := 0
 	obj := NewDecode(data, location, r)
 	return obj.Get()
@@ -0,0 +1,180 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/saichler/l8types/go/types/l8api"
+	"google.golang.org/protobuf/proto"
+)
+
+// Metadata key counts holding the page information of a page.
+const (
+	CountPage     = "Page"     // Zero based page number
+	CountPageSize = "PageSize" // Maximum number of elements in a page, 0 if not paged
+	CountOffset   = "Offset"   // Index of the first element of the page
+	CountHasMore  = "HasMore"  // 1 if more elements follow the page, otherwise 0
+)
+
+// continuationVersion is the format version of a continuation token.
+const continuationVersion = 1
+
+// ErrInvalidContinuation is returned when a continuation token cannot be parsed.
+var ErrInvalidContinuation = errors.New("invalid continuation token")
+
+// PageInfo describes where a page sits in the full result.
+type PageInfo struct {
+	Page         int    // Zero based page number
+	PageSize     int    // Maximum number of elements in a page, 0 if not paged
+	Offset       int    // Index of the first element of the page
+	Total        int    // Number of elements in the full result
+	HasMore      bool   // True if more elements follow the page
+	Continuation string // Token for the next page, empty if there is none
+}
+
+// Page returns the page of the elements selected by the limit and page of
+// the query. Pages are zero based, a negative page is the first page and a
+// zero limit returns all the elements as a single page. The page information
+// is written into the metadata key counts, next to "Total", and, if more
+// elements follow, a continuation token for the next page is set as the
+// HeaderContinuation header.
+//
+// The elements are not sorted or filtered, see Apply for that.
+func (this *Elements) Page(query *l8api.L8Query) *Elements {
+	var limit, page int
+	if query != nil {
+		limit, page = int(query.Limit), int(query.Page)
+	}
+	if page < 0 {
+		page = 0
+	}
+	total := len(this.elements)
+	start, end := pageBounds(limit, page, total)
+
+	result := this.Clone(false)
+	result.elements = make([]*Element, end-start)
+	copy(result.elements, this.elements[start:end])
+	result.keyIndex = nil
+	result.metadata = withTotal(result.metadata, total)
+	counts := result.metadata.KeyCount.Counts
+	counts[CountPage] = float64(page)
+	counts[CountPageSize] = float64(limit)
+	counts[CountOffset] = float64(start)
+	counts[CountHasMore] = 0
+	result.RemoveHeader(HeaderContinuation)
+	if end < total {
+		counts[CountHasMore] = 1
+		next := proto.Clone(query).(*l8api.L8Query)
+		next.Page = int32(page + 1)
+		result.SetHeader(HeaderContinuation, NewContinuationToken(next, end))
+	}
+	return result
+}
+
+// pageBounds returns the start and end index of a page in a slice of total
+// elements. Pages past the end, including those whose start overflows, are
+// empty. Negative pages are treated as the first page.
+func pageBounds(limit, page, total int) (int, int) {
+	if limit <= 0 {
+		return 0, total
+	}
+	if page < 0 {
+		page = 0
+	}
+	if page > total/limit {
+		return total, total
+	}
+	start := page * limit
+	end := total
+	if limit < total-start {
+		end = start + limit
+	}
+	return start, end
+}
+
+// Pages splits the elements into all the pages of the given size. See Page.
+func (this *Elements) Pages(query *l8api.L8Query) []*Elements {
+	if query == nil || query.Limit <= 0 {
+		return []*Elements{this.Page(query)}
+	}
+	limit := int(query.Limit)
+	count := (len(this.elements) + limit - 1) / limit
+	if count == 0 {
+		count = 1
+	}
+	result := make([]*Elements, count)
+	for i := 0; i < count; i++ {
+		q := proto.Clone(query).(*l8api.L8Query)
+		q.Page = int32(i)
+		result[i] = this.Page(q)
+	}
+	return result
+}
+
+// PageInfo returns the page information written by Page, or nil if the
+// container is not a page.
+func (this *Elements) PageInfo() *PageInfo {
+	if this.metadata == nil || this.metadata.KeyCount == nil {
+		return nil
+	}
+	counts := this.metadata.KeyCount.Counts
+	if _, ok := counts[CountPageSize]; !ok {
+		return nil
+	}
+	return &PageInfo{
+		Page:         int(counts[CountPage]),
+		PageSize:     int(counts[CountPageSize]),
+		Offset:       int(counts[CountOffset]),
+		Total:        int(counts["Total"]),
+		HasMore:      counts[CountHasMore] != 0,
+		Continuation: this.HeaderString(HeaderContinuation),
+	}
+}
+
+// NewContinuationToken returns an opaque, URL safe token that encodes a
+// query and the offset of the next element to return. The token is not
+// encrypted or signed, so it must not carry anything the client may not see.
+func NewContinuationToken(query *l8api.L8Query, offset int) string {
+	data := []byte{continuationVersion}
+	data = binary.AppendUvarint(data, uint64(offset))
+	if query != nil {
+		q, _ := proto.MarshalOptions{Deterministic: true}.Marshal(query)
+		data = append(data, q...)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseContinuationToken returns the query and offset encoded in a token
+// minted by NewContinuationToken, or ErrInvalidContinuation.
+func ParseContinuationToken(token string) (*l8api.L8Query, int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) < 2 || data[0] != continuationVersion {
+		return nil, 0, ErrInvalidContinuation
+	}
+	offset, n := binary.Uvarint(data[1:])
+	if n <= 0 || offset > math.MaxInt32 {
+		return nil, 0, ErrInvalidContinuation
+	}
+	query := &l8api.L8Query{}
+	err = proto.Unmarshal(data[1+n:], query)
+	if err != nil {
+		return nil, 0, ErrInvalidContinuation
+	}
+	return query, int(offset), nil
+}
@@ -0,0 +1,216 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// MaxReplicas is the number of replicas a ReplicaSet can hold, numbered
+// from 0 to MaxReplicas-1.
+const MaxReplicas = 64
+
+// ReplicaSet is a set of replica numbers, stored as a bitmask.
+type ReplicaSet uint64
+
+// NewReplicaSet returns a set with the given replicas. Replica numbers of
+// MaxReplicas or more are ignored.
+func NewReplicaSet(replicas ...byte) ReplicaSet {
+	var result ReplicaSet
+	for _, replica := range replicas {
+		result = result.Add(replica)
+	}
+	return result
+}
+
+// Add returns the set with the replica added.
+func (this ReplicaSet) Add(replica byte) ReplicaSet {
+	if replica >= MaxReplicas {
+		return this
+	}
+	return this | 1<<replica
+}
+
+// Has returns true if the replica is in the set.
+func (this ReplicaSet) Has(replica byte) bool {
+	return replica < MaxReplicas && this&(1<<replica) != 0
+}
+
+// Replicas returns the replicas of the set in order.
+func (this ReplicaSet) Replicas() []byte {
+	var result []byte
+	for i := byte(0); i < MaxReplicas; i++ {
+		if this.Has(i) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// SetReplicas marks the container as a replica request for a set of
+// replicas. The replica number is set to the lowest replica of the set.
+func (this *Elements) SetReplicas(replicas ReplicaSet) {
+	this.replicas = replicas
+	this.isReplica = replicas != 0
+	if list := replicas.Replicas(); len(list) > 0 {
+		this.replica = list[0]
+	}
+}
+
+// Replicas returns the replicas targeted by the container: the replica set
+// if one is set, the replica number of a replica request, or an empty set.
+func (this *Elements) Replicas() ReplicaSet {
+	if this.replicas != 0 {
+		return this.replicas
+	}
+	if this.isReplica {
+		return NewReplicaSet(this.replica)
+	}
+	return 0
+}
+
+// HashFunc hashes an element key for partitioning.
+type HashFunc func(key interface{}) uint64
+
+// HashKey is the default HashFunc. It returns the FNV-1a hash of the key
+// formatted with fmt, so equal keys of any type hash the same.
+func HashKey(key interface{}) uint64 {
+	h := fnv.New64a()
+	switch k := key.(type) {
+	case string:
+		h.Write([]byte(k))
+	default:
+		fmt.Fprint(h, key)
+	}
+	return h.Sum64()
+}
+
+// Partition splits a container into n replica requests, one per replica
+// from 0 to n-1, by consistent hashing of the element keys, so that only
+// about 1/n of the keys move when a replica is added. Every part keeps the
+// keys and errors of its elements, in their original order, and carries
+// the query, metadata and headers of the container, with the "Total"
+// metadata count set to the size of the part. Parts may be empty. A nil
+// hashFn uses HashKey.
+//
+// Use Gather to rebuild a result in the original order from the parts, or
+// from the responses of the replicas.
+func Partition(elems ifs.IElements, n int, hashFn HashFunc) ([]*Elements, error) {
+	if n <= 0 || n > MaxReplicas {
+		return nil, errors.New("invalid partition count " + strconv.Itoa(n))
+	}
+	if hashFn == nil {
+		hashFn = HashKey
+	}
+	source, ok := elems.(*Elements)
+	if !ok {
+		return nil, errors.New("partition expects an *Elements")
+	}
+	parts := make([]*Elements, n)
+	for i := range parts {
+		parts[i] = source.Clone(false)
+		parts[i].elements = nil
+		parts[i].keyIndex = nil
+		parts[i].SetReplicas(NewReplicaSet(byte(i)))
+	}
+	for _, o := range source.elements {
+		if o == nil {
+			continue
+		}
+		part := parts[jumpHash(hashFn(o.key), n)]
+		part.elements = append(part.elements, &Element{element: o.element, key: o.key, error: o.error, change: o.change})
+	}
+	for _, part := range parts {
+		part.metadata = withTotal(part.metadata, len(part.elements))
+	}
+	return parts, nil
+}
+
+// Gather merges parts, or the responses to them, into a single container
+// ordered like the original one. Elements are placed by matching their keys
+// with the keys of the original, in order for repeated keys, and keep their
+// errors and change types. Elements whose key is not in the original are
+// appended at the end. The query and headers are taken from the original.
+// The metadata of the parts is merged with MergeMetadata, with the "Total"
+// count set to the number of gathered elements.
+func Gather(original ifs.IElements, parts ...ifs.IElements) *Elements {
+	positions := make(map[interface{}][]int)
+	var keys []interface{}
+	if original != nil {
+		keys = original.Keys()
+	}
+	for i, key := range keys {
+		if hashableKey(key) {
+			positions[key] = append(positions[key], i)
+		}
+	}
+
+	placed := make([]*Element, len(keys))
+	var extra []*Element
+	result := &Elements{}
+	for _, part := range parts {
+		if part == nil {
+			continue
+		}
+		values := part.Elements()
+		errs := part.Errors()
+		var changes []ChangeType
+		if elems, ok := part.(*Elements); ok {
+			changes = elems.ChangeTypes()
+		}
+		for i, key := range part.Keys() {
+			o := &Element{element: values[i], key: key, error: errs[i]}
+			if i < len(changes) {
+				o.change = changes[i]
+			}
+			if hashableKey(key) && len(positions[key]) > 0 {
+				free := positions[key]
+				placed[free[0]] = o
+				positions[key] = free[1:]
+				continue
+			}
+			extra = append(extra, o)
+		}
+		result.metadata = MergeMetadata(result.metadata, part.Metadata())
+	}
+	for _, o := range append(placed, extra...) {
+		if o != nil {
+			result.elements = append(result.elements, o)
+		}
+	}
+	result.metadata = withTotal(result.metadata, len(result.elements))
+	if source, ok := original.(*Elements); ok {
+		result.pquery = source.pquery
+		result.headers = copyHeaders(source.headers)
+	}
+	return result
+}
+
+// jumpHash maps a hash to one of n buckets with the jump consistent hash of
+// Lamping and Veach.
+func jumpHash(key uint64, n int) int {
+	var b, j int64 = -1, 0
+	for j < int64(n) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
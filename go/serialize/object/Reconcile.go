@@ -0,0 +1,246 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8api"
+)
+
+// CountVersion is the metadata key count holding the version of a replica
+// response, used by last-writer-wins when no version path is set.
+const CountVersion = "Version"
+
+// ErrNoQuorum is the element error of a key that fewer replicas than the
+// quorum answered without an error.
+var ErrNoQuorum = errors.New("not enough replicas answered to reach a quorum")
+
+// Candidate is the answer of one replica for a key.
+type Candidate struct {
+	Replica  byte              // Replica number of the response
+	Value    interface{}       // Element value
+	Metadata *l8api.L8MetaData // Metadata of the response
+}
+
+// ConflictResolver picks the winning answer for a key among the answers of
+// the replicas. Candidates are ordered by replica number and never empty.
+type ConflictResolver interface {
+	Resolve(key interface{}, candidates []*Candidate) *Candidate
+}
+
+// Reconciliation is the result of reconciling replica responses.
+type Reconciliation struct {
+	// Elements holds the winning value of every key.
+	Elements *Elements
+	// Disagreed holds, per key, the replicas whose answer differs from the
+	// winner or that did not answer, in order. Keys all replicas agree on
+	// are not listed.
+	Disagreed map[interface{}][]byte
+	// Repairs holds, per stale replica, a replica request with the winning
+	// values of the keys it disagreed on.
+	Repairs map[byte]*Elements
+}
+
+// Reconciler merges the responses of several replicas to the same read.
+type Reconciler struct {
+	resolver ConflictResolver
+	quorum   int
+}
+
+// NewReconciler creates a reconciler using the resolver to pick winners and
+// requiring at least quorum replicas to answer each key.
+func NewReconciler(resolver ConflictResolver, quorum int) *Reconciler {
+	return &Reconciler{resolver: resolver, quorum: quorum}
+}
+
+// Reconcile merges replica responses per element key. Each response is
+// identified by its replica number, see NewReplicaRequest. Answers with an
+// element error do not take part in the resolution, and a key answered by
+// fewer than quorum replicas gets ErrNoQuorum as its element error.
+// Elements without a hashable key are ignored.
+//
+// Returns an error if there are fewer responses than the quorum or if two
+// responses have the same replica number.
+func (this *Reconciler) Reconcile(responses ...ifs.IElements) (*Reconciliation, error) {
+	if len(responses) < this.quorum {
+		return nil, errors.New("expected at least " + strconv.Itoa(this.quorum) +
+			" responses, got " + strconv.Itoa(len(responses)))
+	}
+	replicas := make([]byte, 0, len(responses))
+	seen := make(map[byte]bool, len(responses))
+	for _, response := range responses {
+		if seen[response.Replica()] {
+			return nil, errors.New("duplicate response from replica " + strconv.Itoa(int(response.Replica())))
+		}
+		seen[response.Replica()] = true
+		replicas = append(replicas, response.Replica())
+	}
+	sort.Slice(replicas, func(i, j int) bool { return replicas[i] < replicas[j] })
+
+	keys, answers := this.collect(responses)
+	result := &Reconciliation{
+		Elements:  &Elements{},
+		Disagreed: make(map[interface{}][]byte),
+		Repairs:   make(map[byte]*Elements),
+	}
+	for _, key := range keys {
+		candidates := answers[key]
+		if len(candidates) < this.quorum || len(candidates) == 0 {
+			result.Elements.Add(nil, key, ErrNoQuorum)
+			continue
+		}
+		winner := this.resolver.Resolve(key, candidates)
+		result.Elements.Add(winner.Value, key, nil)
+		for _, replica := range replicas {
+			if agrees(replica, winner, candidates) {
+				continue
+			}
+			result.Disagreed[key] = append(result.Disagreed[key], replica)
+			repair, ok := result.Repairs[replica]
+			if !ok {
+				repair = &Elements{isReplica: true, replica: replica}
+				result.Repairs[replica] = repair
+			}
+			repair.Add(winner.Value, key, nil)
+		}
+	}
+	result.Elements.metadata = withTotal(nil, len(result.Elements.elements))
+	return result, nil
+}
+
+// collect returns the keys in order of first appearance and the answers
+// without an error for every key, ordered by replica number.
+func (this *Reconciler) collect(responses []ifs.IElements) ([]interface{}, map[interface{}][]*Candidate) {
+	var keys []interface{}
+	answers := make(map[interface{}][]*Candidate)
+	for _, response := range responses {
+		values := response.Elements()
+		errs := response.Errors()
+		for i, key := range response.Keys() {
+			if !hashableKey(key) {
+				continue
+			}
+			candidates, ok := answers[key]
+			if !ok {
+				keys = append(keys, key)
+			}
+			if errs[i] == nil && !hasReplica(candidates, response.Replica()) {
+				candidates = append(candidates, &Candidate{Replica: response.Replica(),
+					Value: values[i], Metadata: response.Metadata()})
+			}
+			answers[key] = candidates
+		}
+	}
+	for _, candidates := range answers {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].Replica < candidates[j].Replica
+		})
+	}
+	return keys, answers
+}
+
+// hasReplica returns true if a replica already answered.
+func hasReplica(candidates []*Candidate, replica byte) bool {
+	for _, c := range candidates {
+		if c.Replica == replica {
+			return true
+		}
+	}
+	return false
+}
+
+// agrees returns true if a replica answered with a value equal to the winner.
+func agrees(replica byte, winner *Candidate, candidates []*Candidate) bool {
+	for _, c := range candidates {
+		if c.Replica == replica {
+			_, equal := compareElements(c.Value, winner.Value)
+			return equal
+		}
+	}
+	return false
+}
+
+// lastWriterWins picks the candidate with the highest version.
+type lastWriterWins struct {
+	versionPath string
+}
+
+// NewLastWriterWins creates a resolver that picks the answer with the highest
+// version. The version is read from a numeric property path of the element,
+// or, if the path is empty, from the "Version" metadata count of the response.
+// Ties go to the lowest replica number.
+func NewLastWriterWins(versionPath string) ConflictResolver {
+	return &lastWriterWins{versionPath: versionPath}
+}
+
+// Resolve implements ConflictResolver.
+func (this *lastWriterWins) Resolve(key interface{}, candidates []*Candidate) *Candidate {
+	winner := candidates[0]
+	highest := this.version(winner)
+	for _, c := range candidates[1:] {
+		version := this.version(c)
+		if version > highest {
+			winner, highest = c, version
+		}
+	}
+	return winner
+}
+
+// version returns the version of a candidate, 0 if it has none.
+func (this *lastWriterWins) version(c *Candidate) float64 {
+	if this.versionPath == "" {
+		if c.Metadata == nil || c.Metadata.KeyCount == nil {
+			return 0
+		}
+		return c.Metadata.KeyCount.Counts[CountVersion]
+	}
+	if c.Value == nil {
+		return 0
+	}
+	version, _ := numericValue(propertyValue(c.Value, this.versionPath))
+	return version
+}
+
+// majorityVote picks the value most candidates agree on.
+type majorityVote struct{}
+
+// NewMajorityVote creates a resolver that picks the value the most replicas
+// answered, comparing protobuf values with proto.Equal. Ties go to the value
+// of the lowest replica number.
+func NewMajorityVote() ConflictResolver {
+	return &majorityVote{}
+}
+
+// Resolve implements ConflictResolver.
+func (this *majorityVote) Resolve(key interface{}, candidates []*Candidate) *Candidate {
+	winner := candidates[0]
+	most := 0
+	for _, c := range candidates {
+		votes := 0
+		for _, other := range candidates {
+			if _, equal := compareElements(c.Value, other.Value); equal {
+				votes++
+			}
+		}
+		if votes > most {
+			winner, most = c, votes
+		}
+	}
+	return winner
+}
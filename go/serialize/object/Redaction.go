@@ -0,0 +1,259 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// RedactMode defines how a redacted field is written.
+type RedactMode int
+
+const (
+	// RedactBlank clears the field.
+	RedactBlank RedactMode = iota
+	// RedactHash replaces string and bytes fields with their SHA-256 digest,
+	// so equal secrets can still be correlated without being revealed.
+	// Fields of other kinds are cleared.
+	RedactHash
+)
+
+// RedactionPolicy selects protobuf fields that must not appear in serialized
+// output, e.g. passwords and tokens in audit log copies. Fields are selected
+// per message type name and field path, or by a boolean field option. The
+// policy applies to messages at any depth, including messages nested in
+// slices, maps and other messages.
+//
+// A policy is applied by an encoder with Object.SetRedaction or by
+// Elements.SerializeRedacted. The caller's messages are never mutated,
+// redaction is done on a copy.
+type RedactionPolicy struct {
+	fields  map[string]map[string]RedactMode           // type name -> field path -> mode
+	options map[protoreflect.ExtensionType]RedactMode // field option -> mode
+}
+
+// NewRedactionPolicy creates an empty redaction policy.
+func NewRedactionPolicy() *RedactionPolicy {
+	return &RedactionPolicy{
+		fields:  make(map[string]map[string]RedactMode),
+		options: make(map[protoreflect.ExtensionType]RedactMode),
+	}
+}
+
+// Redact selects a field of the given message type for redaction. The path
+// is dot separated and may go through nested messages, lists and maps of
+// messages, e.g. "credentials.password". Path segments match field names
+// case-insensitively and ignoring underscores, so "my_string", "myString"
+// and "MyString" are the same field.
+func (this *RedactionPolicy) Redact(typeName, fieldPath string, mode RedactMode) *RedactionPolicy {
+	paths, ok := this.fields[typeName]
+	if !ok {
+		paths = make(map[string]RedactMode)
+		this.fields[typeName] = paths
+	}
+	paths[fieldPath] = mode
+	return this
+}
+
+// RedactOption selects for redaction every field whose options carry the
+// given boolean extension set to true, e.g. a custom "(sensitive) = true"
+// field option.
+func (this *RedactionPolicy) RedactOption(option protoreflect.ExtensionType, mode RedactMode) *RedactionPolicy {
+	this.options[option] = mode
+	return this
+}
+
+// Apply returns a redacted copy of the value. Protobuf messages are cloned
+// and redacted, slices and maps are copied with their elements redacted and
+// any other value is returned as is.
+func (this *RedactionPolicy) Apply(any interface{}) interface{} {
+	if any == nil {
+		return nil
+	}
+	pb, ok := any.(proto.Message)
+	if ok {
+		if reflect.ValueOf(any).IsNil() {
+			return any
+		}
+		clone := proto.Clone(pb)
+		this.redactMessage(clone.ProtoReflect())
+		return clone
+	}
+	v := reflect.ValueOf(any)
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() || !redactable(v.Type().Elem()) {
+			return any
+		}
+		result := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			setRedacted(result.Index(i), this.Apply(v.Index(i).Interface()))
+		}
+		return result.Interface()
+	case reflect.Map:
+		if v.IsNil() || !redactable(v.Type().Elem()) {
+			return any
+		}
+		result := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			setRedacted(value, this.Apply(iter.Value().Interface()))
+			result.SetMapIndex(iter.Key(), value)
+		}
+		return result.Interface()
+	}
+	return any
+}
+
+// redactable returns true if values of the type may contain messages.
+func redactable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return t != reflect.TypeOf([]byte{})
+	}
+	return false
+}
+
+// setRedacted sets a redacted value, leaving the zero value for nil.
+func setRedacted(target reflect.Value, value interface{}) {
+	if value != nil {
+		target.Set(reflect.ValueOf(value))
+	}
+}
+
+// redactMessage redacts the selected fields of a message and of all the
+// messages nested in it.
+func (this *RedactionPolicy) redactMessage(m protoreflect.Message) {
+	md := m.Descriptor()
+	for path, mode := range this.fields[string(md.Name())] {
+		redactPath(m, strings.Split(path, "."), mode)
+	}
+	for option, mode := range this.options {
+		for i := 0; i < md.Fields().Len(); i++ {
+			fd := md.Fields().Get(i)
+			if !m.Has(fd) || !proto.HasExtension(fd.Options(), option) {
+				continue
+			}
+			if set, ok := proto.GetExtension(fd.Options(), option).(bool); ok && set {
+				redactField(m, fd, mode)
+			}
+		}
+	}
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil {
+			return true
+		}
+		switch {
+		case fd.IsList():
+			for i := 0; i < v.List().Len(); i++ {
+				this.redactMessage(v.List().Get(i).Message())
+			}
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					this.redactMessage(mv.Message())
+					return true
+				})
+			}
+		default:
+			this.redactMessage(v.Message())
+		}
+		return true
+	})
+}
+
+// redactPath follows the path segments from the message and redacts the
+// field at its end.
+func redactPath(m protoreflect.Message, path []string, mode RedactMode) {
+	fd := fieldByPathName(m.Descriptor(), path[0])
+	if fd == nil || !m.Has(fd) {
+		return
+	}
+	if len(path) == 1 {
+		redactField(m, fd, mode)
+		return
+	}
+	if fd.Message() == nil {
+		return
+	}
+	v := m.Get(fd)
+	switch {
+	case fd.IsList():
+		for i := 0; i < v.List().Len(); i++ {
+			redactPath(v.List().Get(i).Message(), path[1:], mode)
+		}
+	case fd.IsMap():
+		if fd.MapValue().Message() != nil {
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				redactPath(mv.Message(), path[1:], mode)
+				return true
+			})
+		}
+	default:
+		redactPath(m.Mutable(fd).Message(), path[1:], mode)
+	}
+}
+
+// fieldByPathName finds a field by name, case-insensitively and ignoring underscores.
+func fieldByPathName(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	name = normalizeFieldName(name)
+	for i := 0; i < md.Fields().Len(); i++ {
+		fd := md.Fields().Get(i)
+		if normalizeFieldName(string(fd.Name())) == name {
+			return fd
+		}
+	}
+	return nil
+}
+
+// normalizeFieldName lower cases a field name and removes underscores.
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// redactField blanks or hashes a single field.
+func redactField(m protoreflect.Message, fd protoreflect.FieldDescriptor, mode RedactMode) {
+	if mode != RedactHash || fd.IsMap() ||
+		(fd.Kind() != protoreflect.StringKind && fd.Kind() != protoreflect.BytesKind) {
+		m.Clear(fd)
+		return
+	}
+	if fd.IsList() {
+		list := m.Get(fd).List()
+		for i := 0; i < list.Len(); i++ {
+			list.Set(i, hashValue(fd, list.Get(i)))
+		}
+		return
+	}
+	m.Set(fd, hashValue(fd, m.Get(fd)))
+}
+
+// hashValue returns the SHA-256 digest of a string or bytes value, as hex
+// for strings and raw for bytes.
+func hashValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) protoreflect.Value {
+	if fd.Kind() == protoreflect.BytesKind {
+		digest := sha256.Sum256(v.Bytes())
+		return protoreflect.ValueOfBytes(digest[:])
+	}
+	digest := sha256.Sum256([]byte(v.String()))
+	return protoreflect.ValueOfString("sha256:" + hex.EncodeToString(digest[:]))
+}
@@ -0,0 +1,241 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"reflect"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// kindSealed is the type prefix of a sealed (encrypted and authenticated) payload.
+const kindSealed reflect.Kind = 68
+
+// ErrSealTampered is returned when a sealed payload fails authentication,
+// either because it was modified or because the key does not match.
+var ErrSealTampered = errors.New("sealed payload was tampered with or the key does not match")
+
+// ErrNotSealed is returned when a payload given to a sealed decoder is not sealed.
+var ErrNotSealed = errors.New("payload is not sealed")
+
+// Sealer encrypts and authenticates payloads. Open must reject any payload
+// that was not produced by Seal with the same key.
+type Sealer interface {
+	Seal([]byte) ([]byte, error)
+	Open([]byte) ([]byte, error)
+}
+
+// SerializeSealed serializes the container and seals it with the security
+// provider of the resources, so the payload can cross untrusted networks.
+// DeserializeSealed rejects the payload if it was modified in transit.
+func (this *Elements) SerializeSealed(resources ifs.IResources) ([]byte, error) {
+	sealer, err := NewSecuritySealer(resources)
+	if err != nil {
+		return nil, err
+	}
+	return this.SerializeSealedWith(sealer)
+}
+
+// DeserializeSealed opens a payload produced by SerializeSealed and
+// reconstructs the container. Returns ErrSealTampered if the payload fails
+// authentication and ErrNotSealed if it is not sealed at all.
+func (this *Elements) DeserializeSealed(data []byte, resources ifs.IResources) error {
+	sealer, err := NewSecuritySealer(resources)
+	if err != nil {
+		return err
+	}
+	return this.DeserializeSealedWith(data, sealer, resources.Registry())
+}
+
+// SerializeSealedWith serializes the container and seals it with the given
+// sealer, e.g. one created by NewAESGCMSealer with a caller supplied key.
+// Format: kind prefix (int32) followed by the sealed serialized container.
+func (this *Elements) SerializeSealedWith(sealer Sealer) ([]byte, error) {
+	data, err := this.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := sealer.Seal(data)
+	if err != nil {
+		return nil, err
+	}
+	buff := make([]byte, 4+len(sealed))
+	location := 0
+	addInt32(int32(kindSealed), &buff, &location)
+	copy(buff[location:], sealed)
+	return buff, nil
+}
+
+// DeserializeSealedWith opens a payload produced by SerializeSealedWith and
+// reconstructs the container.
+func (this *Elements) DeserializeSealedWith(data []byte, sealer Sealer, r ifs.IRegistry) error {
+	if !IsSealed(data) {
+		return ErrNotSealed
+	}
+	plain, err := sealer.Open(data[4:])
+	if err != nil {
+		return err
+	}
+	return this.Deserialize(plain, r)
+}
+
+// IsSealed returns true if the payload starts with the sealed prefix.
+func IsSealed(data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+	location := 0
+	return reflect.Kind(getInt32(&data, &location)) == kindSealed
+}
+
+// NewAESGCMSealer creates a sealer using AES-GCM with the given 16, 24 or
+// 32 byte key. Each sealed payload carries its own random nonce.
+func NewAESGCMSealer(key []byte) (Sealer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &aesGCMSealer{gcm: gcm}, nil
+}
+
+// aesGCMSealer implements Sealer with AES-GCM.
+type aesGCMSealer struct {
+	gcm cipher.AEAD
+}
+
+// Seal encrypts the payload. Format: nonce followed by the ciphertext and tag.
+func (this *aesGCMSealer) Seal(data []byte) ([]byte, error) {
+	nonce := make([]byte, this.gcm.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	return this.gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// Open decrypts and authenticates the payload.
+func (this *aesGCMSealer) Open(data []byte) ([]byte, error) {
+	if len(data) < this.gcm.NonceSize() {
+		return nil, ErrSealTampered
+	}
+	nonce := data[0:this.gcm.NonceSize()]
+	plain, err := this.gcm.Open(nil, nonce, data[this.gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrSealTampered
+	}
+	return plain, nil
+}
+
+// encrypter is the part of the security provider used for sealing.
+type encrypter interface {
+	Encrypt([]byte) (string, error)
+	Decrypt(string) ([]byte, error)
+}
+
+// NewSecuritySealer creates a sealer that encrypts through the security
+// provider of the resources. The provider's cipher may not be authenticated,
+// so every payload is also authenticated with HMAC-SHA256: a random secret is
+// encrypted along with the payload, the MAC key is derived from it, and the
+// MAC covers both ciphertexts. Forging a MAC requires decrypting the secret,
+// so a modified ciphertext is rejected on Open.
+func NewSecuritySealer(resources ifs.IResources) (Sealer, error) {
+	if resources == nil || resources.Security() == nil {
+		return nil, errors.New("no security provider in resources")
+	}
+	enc, ok := resources.Security().(encrypter)
+	if !ok {
+		return nil, errors.New("security provider does not support encryption")
+	}
+	return &securitySealer{enc: enc}, nil
+}
+
+// securitySealer implements Sealer through the security provider.
+type securitySealer struct {
+	enc encrypter
+}
+
+// sealSecretSize is the size of the random secret a MAC key is derived from.
+const sealSecretSize = 32
+
+// Seal encrypts a random secret and the payload, and authenticates both.
+// Format: encrypted secret size (int32), encrypted secret, encrypted payload
+// and the HMAC-SHA256 of everything before it.
+func (this *securitySealer) Seal(data []byte) ([]byte, error) {
+	secret := make([]byte, sealSecretSize)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return nil, err
+	}
+	encSecret, err := this.enc.Encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+	encData, err := this.enc.Encrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	buff := make([]byte, 4+len(encSecret)+len(encData)+sha256.Size)
+	location := 0
+	addInt32(int32(len(encSecret)), &buff, &location)
+	location += copy(buff[location:], encSecret)
+	location += copy(buff[location:], encData)
+	copy(buff[location:], sealMAC(secret, buff[0:location]))
+	return buff, nil
+}
+
+// Open verifies the MAC of the payload and decrypts it.
+func (this *securitySealer) Open(data []byte) ([]byte, error) {
+	if len(data) < 4+sha256.Size {
+		return nil, ErrSealTampered
+	}
+	location := 0
+	size := int(getInt32(&data, &location))
+	body := data[0 : len(data)-sha256.Size]
+	if size < 0 || location+size > len(body) {
+		return nil, ErrSealTampered
+	}
+	secret, err := this.enc.Decrypt(string(data[location : location+size]))
+	if err != nil || len(secret) != sealSecretSize {
+		return nil, ErrSealTampered
+	}
+	if !hmac.Equal(sealMAC(secret, body), data[len(body):]) {
+		return nil, ErrSealTampered
+	}
+	plain, err := this.enc.Decrypt(string(body[location+size:]))
+	if err != nil {
+		return nil, ErrSealTampered
+	}
+	return plain, nil
+}
+
+// sealMAC returns the HMAC-SHA256 of data, keyed with a key derived from
+// the secret.
+func sealMAC(secret, data []byte) []byte {
+	kdf := hmac.New(sha256.New, secret)
+	kdf.Write([]byte("l8srlz sealer mac key"))
+	mac := hmac.New(sha256.New, kdf.Sum(nil))
+	mac.Write(data)
+	return mac.Sum(nil)
+}
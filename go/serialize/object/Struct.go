@@ -45,7 +45,10 @@ func addStruct(any interface{}, data *[]byte, location *int) error {
 
 	typeName := val.Type().Name()
 
-	pb := any.(proto.Message)
+	pb, ok := any.(proto.Message)
+	if !ok {
+		return errors.New("Cannot serialize " + val.Type().String() + ", it is not a protobuf message")
+	}
 	pbData, err := proto.Marshal(pb)
 	if err != nil {
 		return errors.New("Failed To marshal proto " + typeName + " in protobuf object:" + err.Error())
@@ -0,0 +1,288 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"reflect"
+	"strconv"
+)
+
+// kindTimeSeries is the type prefix of a numeric slice encoded with one of
+// the time-series codecs.
+const kindTimeSeries reflect.Kind = 65
+
+// Time-series codecs.
+const (
+	codecDeltaOfDelta byte = 1 // []int64, e.g. timestamps
+	codecXOR          byte = 2 // []float64, e.g. samples
+)
+
+// AddTimeSeries serializes a numeric slice with a time-series codec.
+// An []int64 is encoded with delta-of-delta, so regularly spaced values such
+// as timestamps take a single bit each. A []float64 is encoded with the XOR
+// (Gorilla) codec, so slowly changing samples take a few bits each.
+//
+// The result is decoded transparently by Get, which returns the original
+// []int64 or []float64.
+//
+// Returns an error for any other type.
+func (this *Object) AddTimeSeries(any interface{}) error {
+	switch any.(type) {
+	case []int64, []float64:
+	case nil:
+		return errors.New("Time series encoding expects []int64 or []float64, got nil")
+	default:
+		return errors.New("Time series encoding expects []int64 or []float64, got " + reflect.TypeOf(any).String())
+	}
+	this.addKind(kindTimeSeries)
+	addTimeSeries(any, this.data, this.location)
+	return nil
+}
+
+// addTimeSeries writes the time-series body.
+// Format: size (int32), codec (byte), encoded length (int32), encoded bits.
+// Nil or empty slices are encoded as -1.
+func addTimeSeries(any interface{}, data *[]byte, location *int) {
+	w := &bitWriter{}
+	var codec byte
+	var size int
+	switch v := any.(type) {
+	case []int64:
+		codec, size = codecDeltaOfDelta, len(v)
+		encodeDeltaOfDelta(v, w)
+	case []float64:
+		codec, size = codecXOR, len(v)
+		encodeXOR(v, w)
+	}
+	if size == 0 {
+		addInt32(int32(-1), data, location)
+		return
+	}
+	addInt32(int32(size), data, location)
+	addByte(codec, data, location)
+	addInt32(int32(len(w.buff)), data, location)
+	checkAndEnlarge(data, location, len(w.buff))
+	copy((*data)[*location:*location+len(w.buff)], w.buff)
+	*location += len(w.buff)
+}
+
+// getTimeSeries deserializes a slice encoded by addTimeSeries.
+func getTimeSeries(data *[]byte, location *int) (interface{}, error) {
+	size := int(getInt32(data, location))
+	if size == -1 || size == 0 {
+		return nil, nil
+	}
+	codec := getByte(data, location)
+	l := int(getInt32(data, location))
+	// Every value after the first takes at least one bit
+	if size < 0 || l < 0 || *location+l > len(*data) || size-1 > l*8 {
+		return nil, errors.New("Invalid time series size " + strconv.Itoa(size))
+	}
+	r := &bitReader{buff: (*data)[*location : *location+l]}
+	*location += l
+	var result interface{}
+	switch codec {
+	case codecDeltaOfDelta:
+		result = decodeDeltaOfDelta(size, r)
+	case codecXOR:
+		result = decodeXOR(size, r)
+	default:
+		return nil, errors.New("Unknown time series codec " + strconv.Itoa(int(codec)))
+	}
+	if r.truncated {
+		return nil, errors.New("Time series data is truncated")
+	}
+	return result, nil
+}
+
+// encodeDeltaOfDelta writes the first value in full, then for each value the
+// difference between its delta and the previous delta, using the shortest of
+// the Gorilla buckets: '0' for no change, '10' + 7 bits, '110' + 9 bits,
+// '1110' + 12 bits and '1111' + 64 bits.
+func encodeDeltaOfDelta(values []int64, w *bitWriter) {
+	if len(values) == 0 {
+		return
+	}
+	w.writeBits(uint64(values[0]), 64)
+	prevDelta := int64(0)
+	for i := 1; i < len(values); i++ {
+		delta := values[i] - values[i-1]
+		dod := delta - prevDelta
+		prevDelta = delta
+		switch {
+		case dod == 0:
+			w.writeBits(0, 1)
+		case fitsBits(dod, 7):
+			w.writeBits(2, 2)
+			w.writeBits(uint64(dod), 7)
+		case fitsBits(dod, 9):
+			w.writeBits(6, 3)
+			w.writeBits(uint64(dod), 9)
+		case fitsBits(dod, 12):
+			w.writeBits(14, 4)
+			w.writeBits(uint64(dod), 12)
+		default:
+			w.writeBits(15, 4)
+			w.writeBits(uint64(dod), 64)
+		}
+	}
+}
+
+// decodeDeltaOfDelta is the inverse of encodeDeltaOfDelta.
+func decodeDeltaOfDelta(size int, r *bitReader) []int64 {
+	values := make([]int64, size)
+	values[0] = int64(r.readBits(64))
+	prevDelta := int64(0)
+	for i := 1; i < size; i++ {
+		n := 0
+		for n < 4 && r.readBits(1) == 1 {
+			n++
+		}
+		var dod int64
+		switch n {
+		case 1:
+			dod = signExtend(r.readBits(7), 7)
+		case 2:
+			dod = signExtend(r.readBits(9), 9)
+		case 3:
+			dod = signExtend(r.readBits(12), 12)
+		case 4:
+			dod = int64(r.readBits(64))
+		}
+		prevDelta += dod
+		values[i] = values[i-1] + prevDelta
+	}
+	return values
+}
+
+// encodeXOR writes the first value in full, then the XOR of each value with
+// its predecessor: '0' when equal, '10' + meaningful bits when they fit the
+// previous leading/trailing zero window, or '11' + 6 bits of leading zeros,
+// 6 bits of meaningful length and the meaningful bits otherwise.
+func encodeXOR(values []float64, w *bitWriter) {
+	if len(values) == 0 {
+		return
+	}
+	prev := math.Float64bits(values[0])
+	w.writeBits(prev, 64)
+	prevLeading, prevTrailing := -1, 0
+	for i := 1; i < len(values); i++ {
+		cur := math.Float64bits(values[i])
+		xor := cur ^ prev
+		prev = cur
+		if xor == 0 {
+			w.writeBits(0, 1)
+			continue
+		}
+		leading := bits.LeadingZeros64(xor)
+		trailing := bits.TrailingZeros64(xor)
+		if prevLeading != -1 && leading >= prevLeading && trailing >= prevTrailing {
+			w.writeBits(2, 2)
+			w.writeBits(xor>>uint(prevTrailing), 64-prevLeading-prevTrailing)
+			continue
+		}
+		meaningful := 64 - leading - trailing
+		w.writeBits(3, 2)
+		w.writeBits(uint64(leading), 6)
+		// A meaningful length of 64 does not fit 6 bits and is written as 0
+		w.writeBits(uint64(meaningful&63), 6)
+		w.writeBits(xor>>uint(trailing), meaningful)
+		prevLeading, prevTrailing = leading, trailing
+	}
+}
+
+// decodeXOR is the inverse of encodeXOR.
+func decodeXOR(size int, r *bitReader) []float64 {
+	values := make([]float64, size)
+	prev := r.readBits(64)
+	values[0] = math.Float64frombits(prev)
+	leading, trailing := 0, 0
+	for i := 1; i < size; i++ {
+		if r.readBits(1) == 0 {
+			values[i] = math.Float64frombits(prev)
+			continue
+		}
+		if r.readBits(1) == 1 {
+			leading = int(r.readBits(6))
+			meaningful := int(r.readBits(6))
+			if meaningful == 0 {
+				meaningful = 64
+			}
+			trailing = 64 - leading - meaningful
+		}
+		xor := r.readBits(64-leading-trailing) << uint(trailing)
+		prev ^= xor
+		values[i] = math.Float64frombits(prev)
+	}
+	return values
+}
+
+// fitsBits returns true if the signed value fits in n bits of two's complement.
+func fitsBits(v int64, n uint) bool {
+	return v >= -(1<<(n-1)) && v < (1<<(n-1))
+}
+
+// signExtend converts the lower n bits of v to a signed value.
+func signExtend(v uint64, n uint) int64 {
+	shift := 64 - n
+	return int64(v<<shift) >> shift
+}
+
+// bitWriter appends values to a byte slice one bit at a time, most
+// significant bit first.
+type bitWriter struct {
+	buff []byte // Encoded bytes
+	free uint   // Number of unused bits in the last byte
+}
+
+// writeBits writes the lower n bits of v.
+func (this *bitWriter) writeBits(v uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if this.free == 0 {
+			this.buff = append(this.buff, 0)
+			this.free = 8
+		}
+		this.free--
+		if (v>>uint(i))&1 == 1 {
+			this.buff[len(this.buff)-1] |= 1 << this.free
+		}
+	}
+}
+
+// bitReader reads values written by bitWriter.
+type bitReader struct {
+	buff      []byte // Encoded bytes
+	pos       int    // Current bit position
+	truncated bool   // A read went past the end of the bytes
+}
+
+// readBits reads n bits and returns them as the lower bits of the result.
+// Bits past the end of the bytes read as 0 and mark the reader truncated.
+func (this *bitReader) readBits(n int) uint64 {
+	var result uint64
+	for i := 0; i < n; i++ {
+		if this.pos/8 >= len(this.buff) {
+			this.truncated = true
+			return result << uint(n-i)
+		}
+		b := this.buff[this.pos/8] >> uint(7-this.pos%8) & 1
+		result = result<<1 | uint64(b)
+		this.pos++
+	}
+	return result
+}
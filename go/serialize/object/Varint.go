@@ -0,0 +1,42 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package object
+
+import "encoding/binary"
+
+// addUVarint serializes an unsigned 64-bit integer using the variable length
+// encoding of encoding/binary, so small values take a single byte.
+func addUVarint(i uint64, data *[]byte, location *int) {
+	checkAndEnlarge(data, location, binary.MaxVarintLen64)
+	*location += binary.PutUvarint((*data)[*location:], i)
+}
+
+// getUVarint deserializes a variable length unsigned 64-bit integer.
+func getUVarint(data *[]byte, location *int) uint64 {
+	result, n := binary.Uvarint((*data)[*location:])
+	*location += n
+	return result
+}
+
+// zigzag maps a signed integer to an unsigned one so that values close to
+// zero, positive or negative, produce short varints.
+func zigzag(i int64) uint64 {
+	return uint64((i << 1) ^ (i >> 63))
+}
+
+// unzigzag is the inverse of zigzag.
+func unzigzag(u uint64) int64 {
+	return int64(u>>1) ^ -int64(u&1)
+}
@@ -38,20 +38,29 @@ func (s *ProtoBuffBinary) Mode() ifs.SerializerMode {
 
 // Marshal serializes any Go value to binary format using the L8S encoder.
 // The resources parameter provides access to the type registry for complex types.
+// The result is compressed and checksummed if enabled with object.SetCompression
+// and object.SetChecksum.
 //
 // Returns the serialized byte slice and nil error on success.
 func (s *ProtoBuffBinary) Marshal(any interface{}, resources ifs.IResources) ([]byte, error) {
 	obj := object.NewEncode()
 	obj.Add(any)
-	return obj.Data(), nil
+	return object.Frame(obj.Data())
 }
 
 // Unmarshal deserializes binary data back to the original Go value.
 // Uses the registry from resources to resolve type information for
-// Protocol Buffers messages and other complex types.
+// Protocol Buffers messages and other complex types. The payload goes
+// through object.Unframe, the inverse of Marshal, so compressed payloads are
+// decompressed, checksums verified and, if required with
+// object.SetChecksumRequired, payloads without a checksum rejected.
 //
 // Returns the deserialized value and nil error on success.
 func (s *ProtoBuffBinary) Unmarshal(data []byte, resources ifs.IResources) (interface{}, error) {
+	data, err := object.Unframe(data)
+	if err != nil {
+		return nil, err
+	}
 	obj := object.NewDecode(data, 0, resources.Registry())
 	return obj.Get()
 }
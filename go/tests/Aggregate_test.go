@@ -0,0 +1,79 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/testtypes"
+)
+
+// TestAggregate verifies grouping and the aggregate functions.
+func TestAggregate(t *testing.T) {
+	elems := testSnapshot(5)
+	elems.Add(nil, "nil", nil)
+	groups := elems.Aggregate("testproto.mybool",
+		object.Aggregation{Func: object.AggregateSum, Field: "MyInt32"},
+		object.Aggregation{Func: object.AggregateMin, Field: "MyInt32"},
+		object.Aggregation{Func: object.AggregateMax, Field: "my_int32"},
+		object.Aggregation{Func: object.AggregateAvg, Field: "MySingle.MyInt64"},
+		object.Aggregation{Func: object.AggregateCount, Field: "MyString"})
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(groups))
+	}
+	odd := groups[0]
+	if odd.Key != "testproto.mybool=false" || odd.Value != false || odd.Count != 3 {
+		t.Errorf("Unexpected group %s %v %d", odd.Key, odd.Value, odd.Count)
+	}
+	if odd.Values["sum(MyInt32)"] != 9 || odd.Values["min(MyInt32)"] != 1 ||
+		odd.Values["max(my_int32)"] != 5 || odd.Values["avg(MySingle.MyInt64)"] != 3 {
+		t.Errorf("Unexpected values %v", odd.Values)
+	}
+	if _, ok := odd.Values["count(MyString)"]; ok {
+		t.Errorf("Expected no result for a non numeric field")
+	}
+	even := groups[1]
+	if even.Count != 2 || even.Values["sum(MyInt32)"] != 6 {
+		t.Errorf("Unexpected group %s %d %v", even.Key, even.Count, even.Values)
+	}
+
+	all := elems.Aggregate("", object.Aggregation{Func: object.AggregateCount, Field: "MyInt32"})
+	if len(all) != 1 || all[0].Key != "*" || all[0].Values["count(MyInt32)"] != 5 {
+		t.Errorf("Unexpected ungrouped result %v", all)
+	}
+}
+
+// TestAddAggregates verifies that the results are written into the metadata
+// and survive serialization.
+func TestAddAggregates(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	elems := testSnapshot(4)
+	elems.AddAggregates("MyBool", object.Aggregation{Func: object.AggregateAvg, Field: "MyInt32"})
+
+	data, err := elems.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	result := &object.Elements{}
+	err = result.Deserialize(data, globals.Registry())
+	if err != nil {
+		t.Fatalf("Failed to deserialize: %v", err)
+	}
+	counts := result.Metadata().KeyCount.Counts
+	if counts["Total"] != 4 || counts["MyBool=true"] != 2 || counts["MyBool=false/avg(MyInt32)"] != 2 {
+		t.Errorf("Unexpected counts %v", counts)
+	}
+}
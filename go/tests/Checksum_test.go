@@ -0,0 +1,122 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8srlz/go/serialize/serializers"
+	"github.com/saichler/l8types/go/testtypes"
+)
+
+// TestChecksum_Elements verifies that a protected Elements payload decodes,
+// and that a flipped byte or a truncated payload is reported as a mismatch.
+func TestChecksum_Elements(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	object.SetChecksum(true)
+	defer object.SetChecksum(false)
+
+	data, err := object.New(nil, testElementsList(10)).Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	if !object.HasChecksum(data) {
+		t.Fatal("Expected payload with checksum")
+	}
+	elems := &object.Elements{}
+	err = elems.Deserialize(data, globals.Registry())
+	if err != nil {
+		t.Fatalf("Failed to deserialize: %v", err)
+	}
+	if len(elems.Elements()) != 10 {
+		t.Fatalf("Expected 10 elements, got %d", len(elems.Elements()))
+	}
+
+	corrupted := make([]byte, len(data))
+	copy(corrupted, data)
+	corrupted[len(corrupted)/2] ^= 0x10
+	err = (&object.Elements{}).Deserialize(corrupted, globals.Registry())
+	if !errors.Is(err, object.ErrChecksumMismatch) {
+		t.Fatalf("Expected checksum mismatch, got %v", err)
+	}
+
+	err = (&object.Elements{}).Deserialize(data[0:len(data)-10], globals.Registry())
+	if !errors.Is(err, object.ErrChecksumMismatch) {
+		t.Fatalf("Expected checksum mismatch for truncated payload, got %v", err)
+	}
+
+	object.SetChecksumRequired(true)
+	defer object.SetChecksumRequired(false)
+	copy(corrupted, data)
+	corrupted[0] ^= 0x01
+	err = (&object.Elements{}).Deserialize(corrupted, globals.Registry())
+	if !errors.Is(err, object.ErrChecksumMismatch) {
+		t.Fatalf("Expected checksum mismatch for corrupted prefix, got %v", err)
+	}
+	err = (&object.Elements{}).Deserialize(data, globals.Registry())
+	if err != nil {
+		t.Fatalf("Failed to deserialize with checksum required: %v", err)
+	}
+}
+
+// TestChecksum_Object verifies that ElemOf verifies a protected payload.
+func TestChecksum_Object(t *testing.T) {
+	obj := object.NewEncode()
+	obj.Add("protected value")
+	data := obj.DataWithChecksum()
+
+	result, err := object.ElemOf(data, globals.Registry())
+	if err != nil || result.(string) != "protected value" {
+		t.Fatalf("Failed to decode protected value: %v", err)
+	}
+
+	data[10] ^= 0x01
+	_, err = object.ElemOf(data, globals.Registry())
+	if !errors.Is(err, object.ErrChecksumMismatch) {
+		t.Fatalf("Expected checksum mismatch, got %v", err)
+	}
+}
+
+// TestChecksum_RequiredSerializer verifies that the binary serializer and
+// ElemOf reject payloads without a checksum when checksums are required.
+func TestChecksum_RequiredSerializer(t *testing.T) {
+	plain, err := serializers.Default.Marshal("unprotected", globals)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	object.SetChecksum(true)
+	defer object.SetChecksum(false)
+	protected, err := serializers.Default.Marshal("protected", globals)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	object.SetChecksumRequired(true)
+	defer object.SetChecksumRequired(false)
+	_, err = serializers.Default.Unmarshal(plain, globals)
+	if !errors.Is(err, object.ErrChecksumMismatch) {
+		t.Errorf("Expected checksum mismatch from Unmarshal, got %v", err)
+	}
+	_, err = object.ElemOf(plain, globals.Registry())
+	if !errors.Is(err, object.ErrChecksumMismatch) {
+		t.Errorf("Expected checksum mismatch from ElemOf, got %v", err)
+	}
+	result, err := serializers.Default.Unmarshal(protected, globals)
+	if err != nil || result.(string) != "protected" {
+		t.Errorf("Failed to unmarshal a protected payload: %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	. "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8types/go/types/l8api"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestChunks_RoundTrip verifies that chunks stay under the limit and are
+// reassembled out of order, with missing chunks reported.
+func TestChunks_RoundTrip(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	list := make([]*testtypes.TestProto, 20)
+	for i := range list {
+		list[i] = CreateTestModelInstance(i + 1)
+	}
+	elems := object.NewQueryResult(list, &l8api.L8MetaData{KeyCount: &l8api.L8Count{
+		Counts: map[string]float64{"Total": 100}}}).(*object.Elements)
+	elems.Add(nil, "failed", errors.New("not found"))
+	elems.SetHeader(object.HeaderTraceID, "trace-1")
+
+	chunks, err := elems.SerializeChunks(2048)
+	if err != nil {
+		t.Fatalf("Failed to chunk: %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("Expected several chunks, got %d", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > 2048 {
+			t.Errorf("Chunk %d is %d bytes", i, len(chunk))
+		}
+	}
+
+	reassembler := object.NewReassembler(globals.Registry())
+	for i := len(chunks) - 1; i > 0; i-- {
+		result, err := reassembler.Add(chunks[i])
+		if err != nil || result != nil {
+			t.Fatalf("Unexpected result for chunk %d: %v", i, err)
+		}
+	}
+	pending := reassembler.Pending()
+	if len(pending) != 1 {
+		t.Fatalf("Expected 1 pending container, got %v", pending)
+	}
+	missing := reassembler.Missing(pending[0])
+	if len(missing) != 1 || missing[0] != 0 {
+		t.Errorf("Expected chunk 0 missing, got %v", missing)
+	}
+
+	result, err := reassembler.Add(chunks[0])
+	if err != nil || result == nil {
+		t.Fatalf("Expected the rebuilt container: %v", err)
+	}
+	if result.Len() != 21 {
+		t.Fatalf("Expected 21 elements, got %d", result.Len())
+	}
+	for i := 0; i < 20; i++ {
+		if !proto.Equal(result.Elements()[i].(proto.Message), elems.Elements()[i].(proto.Message)) {
+			t.Errorf("Element %d does not match", i)
+		}
+	}
+	if result.Errors()[20] == nil || result.Keys()[20] != "failed" {
+		t.Errorf("Expected the error of the last element")
+	}
+	if result.Metadata().KeyCount.Counts["Total"] != 100 || result.HeaderString(object.HeaderTraceID) != "trace-1" {
+		t.Errorf("Metadata or headers were not restored")
+	}
+	if _, ok := result.Header(object.HeaderChunkID); ok {
+		t.Errorf("Expected the chunk headers to be removed")
+	}
+	if len(reassembler.Pending()) != 0 {
+		t.Errorf("Expected no pending containers")
+	}
+}
+
+// TestChunks_TooLarge verifies that an element larger than the limit fails.
+func TestChunks_TooLarge(t *testing.T) {
+	_, err := testSnapshot(2).SerializeChunks(64)
+	if err != object.ErrChunkTooLarge {
+		t.Errorf("Expected ErrChunkTooLarge, got %v", err)
+	}
+}
+
+// TestChunks_Framed verifies that chunks stay under the limit with
+// compression and checksums enabled.
+func TestChunks_Framed(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	object.SetCompression(object.GZip, 256)
+	object.SetChecksum(true)
+	defer object.SetCompression(nil, 0)
+	defer object.SetChecksum(false)
+	chunks, err := testSnapshot(30).SerializeChunks(1024)
+	if err != nil {
+		t.Fatalf("Failed to chunk: %v", err)
+	}
+	reassembler := object.NewReassembler(globals.Registry())
+	var result *object.Elements
+	for i, chunk := range chunks {
+		if len(chunk) > 1024 {
+			t.Errorf("Chunk %d is %d bytes", i, len(chunk))
+		}
+		result, err = reassembler.Add(chunk)
+		if err != nil {
+			t.Fatalf("Failed to add chunk %d: %v", i, err)
+		}
+	}
+	if result == nil || result.Len() != 30 {
+		t.Fatalf("Expected the rebuilt container of 30 elements")
+	}
+}
+
+// TestChunks_Limits verifies that the oldest incomplete container is
+// discarded when too many are pending, and that incomplete containers expire.
+func TestChunks_Limits(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	reassembler := object.NewReassembler(globals.Registry())
+	reassembler.SetLimits(2, 0)
+	var first string
+	for i := 0; i < 3; i++ {
+		chunks, err := testSnapshot(20).SerializeChunks(1024)
+		if err != nil || len(chunks) < 2 {
+			t.Fatalf("Expected several chunks: %v", err)
+		}
+		reassembler.Add(chunks[0])
+		if i == 0 {
+			first = reassembler.Pending()[0]
+		}
+		time.Sleep(time.Millisecond)
+	}
+	pending := reassembler.Pending()
+	if len(pending) != 2 || pending[0] == first || pending[1] == first {
+		t.Errorf("Expected the oldest container to be discarded, got %v", pending)
+	}
+
+	reassembler.SetLimits(0, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	if len(reassembler.Pending()) != 0 {
+		t.Errorf("Expected the pending containers to expire")
+	}
+}
+
+// TestChunks_MaxChunks verifies that a chunk announcing more chunks than
+// allowed is rejected before anything is allocated for its container.
+func TestChunks_MaxChunks(t *testing.T) {
+	chunk := &object.Elements{}
+	chunk.Add("a", "key-a", nil)
+	chunk.SetHeader(object.HeaderChunkID, "crafted")
+	chunk.SetHeader(object.HeaderChunkSeq, int32(0))
+	chunk.SetHeader(object.HeaderChunkCount, int32(math.MaxInt32))
+	data, err := chunk.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	reassembler := object.NewReassembler(globals.Registry())
+	_, err = reassembler.Add(data)
+	if err == nil {
+		t.Fatalf("Expected the chunk to be rejected")
+	}
+	if len(reassembler.Pending()) != 0 || reassembler.Missing("crafted") != nil {
+		t.Errorf("Expected no pending container")
+	}
+
+	reassembler.SetMaxChunks(2)
+	chunks, err := testSnapshot(20).SerializeChunks(1024)
+	if err != nil || len(chunks) < 3 {
+		t.Fatalf("Expected more than two chunks: %v", err)
+	}
+	_, err = reassembler.Add(chunks[0])
+	if err == nil {
+		t.Errorf("Expected the chunk to exceed the configured maximum")
+	}
+}
@@ -0,0 +1,136 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	. "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/testtypes"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestColumnar_RoundTrip verifies that a slice of protos, including nil
+// rows, is rebuilt exactly after columnar encoding.
+func TestColumnar_RoundTrip(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	val := make([]*testtypes.TestProto, 0)
+	for i := 0; i < 50; i++ {
+		if i == 7 {
+			val = append(val, nil)
+			continue
+		}
+		val = append(val, CreateTestModelInstance(i))
+	}
+
+	obj := object.NewEncode()
+	err := obj.AddColumnar(val)
+	if err != nil {
+		t.Fatalf("Failed to add columnar: %v", err)
+	}
+	decoded := object.NewDecode(obj.Data(), 0, globals.Registry())
+	dval, err := decoded.Get()
+	if err != nil {
+		t.Fatalf("Failed to get columnar: %v", err)
+	}
+	res := dval.([]*testtypes.TestProto)
+	if len(res) != len(val) {
+		t.Fatalf("Length mismatch: expected %d, got %d", len(val), len(res))
+	}
+	for i := range val {
+		if val[i] == nil {
+			if res[i] != nil {
+				t.Errorf("Expected nil at index %d", i)
+			}
+			continue
+		}
+		if !proto.Equal(val[i], res[i]) {
+			t.Errorf("Value mismatch at index %d", i)
+		}
+	}
+}
+
+// TestColumnar_Smaller verifies that near-identical rows encode to fewer
+// bytes than the row oriented slice encoding.
+func TestColumnar_Smaller(t *testing.T) {
+	val := make([]*testtypes.TestProto, 1000)
+	for i := range val {
+		val[i] = &testtypes.TestProto{MyString: "host-1", MyInt32: 3, MyInt64: int64(1700000000 + i*60), MyBool: true}
+	}
+	rowData, err := object.DataOf(val)
+	if err != nil {
+		t.Fatalf("Failed to serialize rows: %v", err)
+	}
+	obj := object.NewEncode()
+	err = obj.AddColumnar(val)
+	if err != nil {
+		t.Fatalf("Failed to add columnar: %v", err)
+	}
+	if len(obj.Data())*10 > len(rowData) {
+		t.Errorf("Expected columnar to be much smaller: %d vs %d", len(obj.Data()), len(rowData))
+	}
+}
+
+// TestColumnar_Errors verifies that non proto and mixed slices are rejected.
+func TestColumnar_Errors(t *testing.T) {
+	obj := object.NewEncode()
+	if obj.AddColumnar([]string{"a", "b"}) == nil {
+		t.Error("Expected error for a slice of strings")
+	}
+	obj = object.NewEncode()
+	mixed := []proto.Message{&testtypes.TestProto{}, &testtypes.TestProtoList{}}
+	if obj.AddColumnar(mixed) == nil {
+		t.Error("Expected error for a slice of mixed types")
+	}
+}
+
+// TestColumnar_Corrupted verifies that truncated and corrupted payloads
+// return an error instead of panicking or allocating unbounded memory.
+func TestColumnar_Corrupted(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	val := make([]*testtypes.TestProto, 10)
+	for i := range val {
+		val[i] = CreateTestModelInstance(i)
+	}
+	obj := object.NewEncode()
+	err := obj.AddColumnar(val)
+	if err != nil {
+		t.Fatalf("Failed to add columnar: %v", err)
+	}
+	data := obj.Data()
+	for size := 5; size < len(data); size++ {
+		_, err = object.NewDecode(data[:size], 0, globals.Registry()).Get()
+		if err == nil {
+			t.Errorf("Expected an error for a payload truncated to %d bytes", size)
+		}
+	}
+
+	rows := append([]byte(nil), data...)
+	binary.BigEndian.PutUint32(rows[4:], math.MaxInt32)
+	_, err = object.NewDecode(rows, 0, globals.Registry()).Get()
+	if err == nil {
+		t.Error("Expected an error for a huge row count")
+	}
+
+	// Corrupted bytes may still decode, but must never panic
+	for i := 8; i < len(data); i++ {
+		corrupted := append([]byte(nil), data...)
+		corrupted[i] ^= 0xff
+		object.NewDecode(corrupted, 0, globals.Registry()).Get()
+	}
+}
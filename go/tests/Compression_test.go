@@ -0,0 +1,139 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8srlz/go/serialize/serializers"
+	. "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/testtypes"
+	"google.golang.org/protobuf/proto"
+)
+
+// testElementsList creates a list of test protos for the Elements tests.
+func testElementsList(size int) []*testtypes.TestProto {
+	list := make([]*testtypes.TestProto, size)
+	for i := range list {
+		list[i] = CreateTestModelInstance(i)
+	}
+	return list
+}
+
+// TestCompression_Elements verifies that Elements payloads above the
+// threshold are compressed and decode back to the same elements.
+func TestCompression_Elements(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	defer object.SetCompression(nil, 0)
+	for _, c := range []object.Compressor{object.GZip, object.Deflate} {
+		object.SetCompression(c, 1024)
+		list := testElementsList(100)
+		plainData, err := object.DataOf(list)
+		if err != nil {
+			t.Fatalf("Failed to serialize: %v", err)
+		}
+		data, err := object.New(nil, list).Serialize()
+		if err != nil {
+			t.Fatalf("Failed to serialize: %v", err)
+		}
+		if !object.IsCompressed(data) || len(data) >= len(plainData) {
+			t.Fatalf("Expected compressed payload, got %d bytes", len(data))
+		}
+		elems := &object.Elements{}
+		err = elems.Deserialize(data, globals.Registry())
+		if err != nil {
+			t.Fatalf("Failed to deserialize: %v", err)
+		}
+		for i, elem := range elems.Elements() {
+			if !proto.Equal(elem.(proto.Message), list[i]) {
+				t.Fatalf("Element mismatch at index %d", i)
+			}
+		}
+	}
+}
+
+// TestCompression_Threshold verifies that small payloads stay uncompressed
+// and that payloads written without compression still decode.
+func TestCompression_Threshold(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	object.SetCompression(object.GZip, 1024*1024)
+	defer object.SetCompression(nil, 0)
+	data, err := object.New(nil, testElementsList(2)).Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	if object.IsCompressed(data) {
+		t.Fatal("Expected payload below the threshold to be uncompressed")
+	}
+	elems := &object.Elements{}
+	err = elems.Deserialize(data, globals.Registry())
+	if err != nil {
+		t.Fatalf("Failed to deserialize: %v", err)
+	}
+	if len(elems.Elements()) != 2 {
+		t.Fatalf("Expected 2 elements, got %d", len(elems.Elements()))
+	}
+}
+
+// TestCompression_Serializer verifies that the binary serializer compresses
+// and that ElemOf decodes a compressed payload transparently.
+func TestCompression_Serializer(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	object.SetCompression(object.Deflate, 64)
+	defer object.SetCompression(nil, 0)
+	list := testElementsList(20)
+	data, err := serializers.Default.Marshal(list, globals)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if !object.IsCompressed(data) {
+		t.Fatal("Expected compressed payload")
+	}
+	result, err := serializers.Default.Unmarshal(data, globals)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if len(result.([]*testtypes.TestProto)) != len(list) {
+		t.Fatal("Length mismatch after unmarshal")
+	}
+	result, err = object.ElemOf(data, globals.Registry())
+	if err != nil || len(result.([]*testtypes.TestProto)) != len(list) {
+		t.Fatalf("Failed to decode with ElemOf: %v", err)
+	}
+}
+
+// TestCompression_MaxDataSize verifies that a payload decompressing to more
+// than MaxDataSize bytes is rejected.
+func TestCompression_MaxDataSize(t *testing.T) {
+	object.SetCompression(object.GZip, 0)
+	defer object.SetCompression(nil, 0)
+	data, err := object.Compress(make([]byte, 4096))
+	if err != nil || !object.IsCompressed(data) {
+		t.Fatalf("Failed to compress: %v", err)
+	}
+	saved := object.MaxDataSize
+	object.MaxDataSize = 1024
+	defer func() { object.MaxDataSize = saved }()
+	_, err = object.Decompress(data)
+	if err != object.ErrDataTooLarge {
+		t.Fatalf("Expected ErrDataTooLarge, got %v", err)
+	}
+	object.MaxDataSize = 4096
+	plain, err := object.Decompress(data)
+	if err != nil || len(plain) != 4096 {
+		t.Fatalf("Expected 4096 bytes at the limit, got %d: %v", len(plain), err)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	. "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/testtypes"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestDelta_RoundTrip verifies field, nested, list and map edits, and that
+// the delta is smaller than the full message.
+func TestDelta_RoundTrip(t *testing.T) {
+	base := CreateTestModelInstance(1)
+	current := proto.Clone(base).(*testtypes.TestProto)
+	current.MyInt32 = 42
+	current.MyBool = false
+	current.MyString = ""
+	current.MySingle.MyString = "edited"
+	current.MyStringSlice = append(current.MyStringSlice, "c1")
+	current.MyInt32Slice = []int32{9}
+	current.MyStringToInt32Map["new"] = 7
+	delete(current.MyStringToModelMap, "m1")
+	current.MyStringToModelMap["m2"] = &testtypes.TestProtoSub{MyString: "added"}
+
+	delta, err := object.EncodeDelta(base, current)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	result, err := object.ApplyDelta(base, delta)
+	if err != nil {
+		t.Fatalf("Failed to apply: %v", err)
+	}
+	if !proto.Equal(result, current) {
+		t.Errorf("Result does not match\n%v\n%v", result, current)
+	}
+	if base.MyInt32 != 1 || base.MySingle.MyString != "sub-1" {
+		t.Errorf("Base was modified")
+	}
+
+	same, err := object.EncodeDelta(base, proto.Clone(base))
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	result, err = object.ApplyDelta(base, same)
+	if err != nil || !proto.Equal(result, base) {
+		t.Errorf("Expected an empty delta to return the base: %v", err)
+	}
+}
+
+// TestDelta_Size verifies that a small edit of a large message gives a small delta.
+func TestDelta_Size(t *testing.T) {
+	base := CreateTestModelInstance(1)
+	for i := 0; i < 500; i++ {
+		base.MyStringSlice = append(base.MyStringSlice, "item-"+strconv.Itoa(i))
+	}
+	current := proto.Clone(base).(*testtypes.TestProto)
+	current.MySingle.MyInt64 = 1000
+	current.MyStringSlice = append(current.MyStringSlice, "last")
+
+	delta, err := object.EncodeDelta(base, current)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	full, _ := proto.Marshal(current)
+	if len(delta)*10 > len(full) {
+		t.Errorf("Delta of %d bytes for a message of %d bytes", len(delta), len(full))
+	}
+	result, err := object.ApplyDelta(base, delta)
+	if err != nil || !proto.Equal(result, current) {
+		t.Errorf("Result does not match: %v", err)
+	}
+}
+
+// TestDelta_WrongBase verifies that a delta applied to another base, or a
+// malformed delta, is rejected.
+func TestDelta_WrongBase(t *testing.T) {
+	base := CreateTestModelInstance(1)
+	current := proto.Clone(base).(*testtypes.TestProto)
+	current.MyInt64 = 99
+	delta, err := object.EncodeDelta(base, current)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	_, err = object.ApplyDelta(CreateTestModelInstance(2), delta)
+	if err != object.ErrDeltaBaseMismatch {
+		t.Errorf("Expected ErrDeltaBaseMismatch, got %v", err)
+	}
+	_, err = object.ApplyDelta(&testtypes.TestProtoSub{}, delta)
+	if err != object.ErrDeltaBaseMismatch {
+		t.Errorf("Expected ErrDeltaBaseMismatch for another type, got %v", err)
+	}
+	for size := 0; size < len(delta); size++ {
+		_, err = object.ApplyDelta(base, delta[:size])
+		if err != object.ErrInvalidDelta {
+			t.Errorf("Expected ErrInvalidDelta for a delta truncated to %d bytes, got %v", size, err)
+		}
+	}
+	_, err = object.EncodeDelta(base, &testtypes.TestProtoSub{})
+	if err == nil {
+		t.Errorf("Expected an error for messages of different types")
+	}
+}
+
+// TestDelta_WrongFieldKind verifies that an operation that does not fit the
+// kind of its field is rejected.
+func TestDelta_WrongFieldKind(t *testing.T) {
+	base := CreateTestModelInstance(1)
+	current := proto.Clone(base).(*testtypes.TestProto)
+	current.MyString = "changed"
+	delta, err := object.EncodeDelta(base, current)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	// The only operation sets field 1, a string
+	index := bytes.LastIndex(delta, []byte{2, 0, 0, 0, 1})
+	if index < 0 {
+		t.Fatalf("Expected a set operation on field 1")
+	}
+	for _, op := range []byte{3, 4, 5, 6} {
+		crafted := append([]byte(nil), delta...)
+		crafted[index] = op
+		_, err = object.ApplyDelta(base, crafted)
+		if err != object.ErrInvalidDelta {
+			t.Errorf("Expected ErrInvalidDelta for op %d on a string field, got %v", op, err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/testtypes"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestDiff verifies added, removed and changed elements, the changed field
+// paths, and that the diff survives serialization.
+func TestDiff(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	old := testSnapshot(4)
+	current := testSnapshot(5)
+	current.RemoveKey("string-1")
+	changed, _ := current.ByKey("string-3")
+	changed.(*testtypes.TestProto).MyInt32 = 33
+	changed.(*testtypes.TestProto).MySingle.MyString = "changed"
+
+	diff := object.Diff(old, current)
+	data, err := diff.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	result := &object.Elements{}
+	err = result.Deserialize(data, globals.Registry())
+	if err != nil {
+		t.Fatalf("Failed to deserialize: %v", err)
+	}
+	if result.Len() != 3 {
+		t.Fatalf("Expected 3 changes, got %d", result.Len())
+	}
+
+	c := result.Elements()[0].(*object.Change)
+	if c.Type != object.ChangeChanged || c.Key != "string-3" {
+		t.Errorf("Unexpected change %v %v", c.Type, c.Key)
+	}
+	if !reflect.DeepEqual(c.Paths, []string{"my_int32", "my_single.my_string"}) {
+		t.Errorf("Unexpected paths %v", c.Paths)
+	}
+	if !proto.Equal(c.New.(proto.Message), changed.(proto.Message)) || c.Old.(*testtypes.TestProto).MyInt32 != 3 {
+		t.Errorf("Unexpected values")
+	}
+
+	a := result.Elements()[1].(*object.Change)
+	if a.Type != object.ChangeAdded || a.Key != "string-5" || a.Old != nil || a.New == nil {
+		t.Errorf("Unexpected added change %v %v", a.Type, a.Key)
+	}
+	r := result.Elements()[2].(*object.Change)
+	if r.Type != object.ChangeRemoved || result.Keys()[2] != "string-1" || r.New != nil {
+		t.Errorf("Unexpected removed change %v %v", r.Type, r.Key)
+	}
+
+	counts := result.Metadata().KeyCount.Counts
+	if counts["Added"] != 1 || counts["Removed"] != 1 || counts["Changed"] != 1 {
+		t.Errorf("Unexpected counts %v", counts)
+	}
+	if object.Diff(old, testSnapshot(4)).Len() != 0 {
+		t.Errorf("Expected no changes between equal snapshots")
+	}
+}
@@ -0,0 +1,168 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+)
+
+// testQuotaError is a sentinel error with its own type, for errors.As.
+type testQuotaError struct {
+	limit int
+}
+
+func (this *testQuotaError) Error() string {
+	return fmt.Sprintf("quota of %d exceeded", this.limit)
+}
+
+var (
+	errTestNotFound  = errors.New("not found")
+	errTestTransient = errors.New("transient failure")
+	errTestQuota     = &testQuotaError{limit: 10}
+)
+
+func init() {
+	object.RegisterError(404, errTestNotFound, false)
+	object.RegisterError(503, errTestTransient, true)
+	object.RegisterError(429, errTestQuota, true)
+}
+
+// TestElementError_Elements verifies that per element errors keep their
+// code, sentinel identity, retryable flag, details and causes through
+// Serialize and Deserialize.
+func TestElementError_Elements(t *testing.T) {
+	elems := &object.Elements{}
+	elems.Add("a", "key-a", fmt.Errorf("loading key-a: %w", errTestNotFound))
+	elems.Add("b", "key-b", object.NewElementError(503, "backend busy").
+		WithDetail("shard", "7").WithCause(errors.New("connection reset")))
+	elems.Add("c", "key-c", nil)
+
+	data, err := elems.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	result := &object.Elements{}
+	err = result.Deserialize(data, globals.Registry())
+	if err != nil {
+		t.Fatalf("Failed to deserialize: %v", err)
+	}
+	errs := result.Errors()
+
+	if !errors.Is(errs[0], errTestNotFound) || errors.Is(errs[0], errTestTransient) {
+		t.Errorf("Expected not found error, got %v", errs[0])
+	}
+	if errs[0].Error() != "loading key-a: not found" || object.IsRetryable(errs[0]) {
+		t.Errorf("Unexpected message or retryable flag: %v", errs[0])
+	}
+
+	var ee *object.ElementError
+	if !errors.As(errs[1], &ee) {
+		t.Fatalf("Expected an ElementError, got %T", errs[1])
+	}
+	if ee.Code != 503 || !ee.Retryable || ee.Details["shard"] != "7" || !errors.Is(ee, errTestTransient) {
+		t.Errorf("Unexpected structured error: %+v", ee)
+	}
+	if ee.Cause == nil || ee.Cause.Error() != "connection reset" {
+		t.Errorf("Expected cause to be kept, got %v", ee.Cause)
+	}
+	if errs[2] != nil {
+		t.Errorf("Expected nil error, got %v", errs[2])
+	}
+}
+
+// TestElementError_LegacySlot verifies that the element error slot keeps
+// the plain message, so readers that predate structured errors can still
+// decode the payload.
+func TestElementError_LegacySlot(t *testing.T) {
+	elems := &object.Elements{}
+	elems.Add("a", "key-a", object.NewElementError(503, "backend busy"))
+
+	data, err := elems.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	data, err = object.Unframe(data)
+	if err != nil {
+		t.Fatalf("Failed to unframe: %v", err)
+	}
+	obj := object.NewDecode(data, 0, globals.Registry())
+	for i, expected := range []interface{}{1, "a", "key-a", "backend busy"} {
+		value, err := obj.Get()
+		if err != nil {
+			t.Fatalf("Failed to decode value %d: %v", i, err)
+		}
+		if value != expected {
+			t.Errorf("Expected %v at %d, got %v (%T)", expected, i, value, value)
+		}
+	}
+}
+
+// TestElementError_As verifies that errors.As resolves the sentinel
+// registered for the code of a decoded error.
+func TestElementError_As(t *testing.T) {
+	elems := &object.Elements{}
+	elems.Add("a", "key-a", fmt.Errorf("writing key-a: %w", errTestQuota))
+
+	data, err := elems.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	result := &object.Elements{}
+	err = result.Deserialize(data, globals.Registry())
+	if err != nil {
+		t.Fatalf("Failed to deserialize: %v", err)
+	}
+	var quota *testQuotaError
+	if !errors.As(result.Error(), &quota) || quota != errTestQuota {
+		t.Errorf("Expected the quota sentinel, got %v", quota)
+	}
+	if errors.As(object.NewElementError(404, "missing"), &quota) {
+		t.Error("Expected no match for a sentinel of another type")
+	}
+}
+
+// TestElementError_Object verifies that errors serialize as values.
+func TestElementError_Object(t *testing.T) {
+	data, err := object.DataOf(fmt.Errorf("wrapped: %w", errTestTransient))
+	if err != nil {
+		t.Fatalf("Failed to serialize error: %v", err)
+	}
+	result, err := object.ElemOf(data, globals.Registry())
+	if err != nil {
+		t.Fatalf("Failed to deserialize error: %v", err)
+	}
+	decoded := result.(*object.ElementError)
+	if !errors.Is(decoded, errTestTransient) || !object.IsRetryable(decoded) {
+		t.Errorf("Expected transient error, got %+v", decoded)
+	}
+}
+
+// TestElementError_Joined verifies that joined errors keep the code of the
+// joined error that has one.
+func TestElementError_Joined(t *testing.T) {
+	coded := object.NewElementError(7, "coded").WithDetail("node", "n1")
+	single := object.ErrorOf(errors.Join(nil, coded))
+	if single != coded {
+		t.Errorf("Expected a single joined error as is, got %+v", single)
+	}
+	joined := object.ErrorOf(errors.Join(errors.New("plain"), coded))
+	if joined.Code != 7 || joined.Details["node"] != "n1" || joined.Cause.Error() != "plain" {
+		t.Errorf("Expected the joined code and details, got %+v", joined)
+	}
+}
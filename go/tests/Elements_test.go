@@ -24,6 +24,7 @@ import (
 	. "github.com/saichler/l8test/go/infra/t_resources"
 	"github.com/saichler/l8types/go/ifs"
 	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8types/go/types/l8api"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
@@ -253,6 +254,79 @@ func TestAsListError(t *testing.T) {
 	}
 }
 
+// widget and widgetList are used to test AsList with a list type that has
+// no List field.
+type widget struct{}
+type widgetList struct {
+	Items []*widget
+}
+
+// TestAsListInvalid tests that AsList returns errors instead of panicking for
+// mixed element types, non-pointer elements and a missing List field.
+func TestAsListInvalid(t *testing.T) {
+	res, _ := CreateResources(25000, 2, ifs.Info_Level)
+	res.Registry().Register(&testtypes.TestProto{})
+	res.Registry().Register(&testtypes.TestProtoList{})
+	res.Registry().Register(&widget{})
+	res.Registry().Register(&widgetList{})
+
+	mixed := &object.Elements{}
+	mixed.Add(CreateTestModelInstance(1), nil, nil)
+	mixed.Add(&l8api.L8Query{}, nil, nil)
+	_, err := mixed.AsList(res.Registry())
+	if err == nil {
+		Log.Fail(t, "Expected error for mixed element types")
+	}
+
+	_, err = object.New(nil, []string{"a", "b"}).AsList(res.Registry())
+	if err == nil {
+		Log.Fail(t, "Expected error for non-pointer elements")
+	}
+
+	_, err = object.New(nil, &widget{}).AsList(res.Registry())
+	if err == nil {
+		Log.Fail(t, "Expected error for a list type without a List field")
+	}
+}
+
+// TestFromList tests unpacking a list structure back into elements.
+func TestFromList(t *testing.T) {
+	res, _ := CreateResources(25000, 2, ifs.Info_Level)
+	res.Registry().Register(&testtypes.TestProto{})
+	res.Registry().Register(&testtypes.TestProtoList{})
+	metadata := &l8api.L8MetaData{KeyCount: &l8api.L8Count{Counts: map[string]float64{"Total": 2}}}
+	elemList := []*testtypes.TestProto{CreateTestModelInstance(2), CreateTestModelInstance(3)}
+	list, err := object.NewQueryResult(elemList, metadata).AsList(res.Registry())
+	if err != nil {
+		Log.Fail(t, "AsList failed:", err)
+		return
+	}
+
+	elems, err := object.FromList(list)
+	if err != nil {
+		Log.Fail(t, "FromList failed:", err)
+		return
+	}
+	if len(elems.Elements()) != 2 || !proto.Equal(elems.Elements()[1].(proto.Message), elemList[1]) {
+		Log.Fail(t, "Unexpected elements:", elems.Elements())
+	}
+	if elems.Keys()[0] != 0 || elems.Keys()[1] != 1 {
+		Log.Fail(t, "Expected index keys, got", elems.Keys())
+	}
+	if elems.Metadata().KeyCount.Counts["Total"] != 2 {
+		Log.Fail(t, "Metadata was not unpacked")
+	}
+
+	_, err = object.FromList(CreateTestModelInstance(1))
+	if err == nil {
+		Log.Fail(t, "Expected error for a struct without a List field")
+	}
+	_, err = object.FromList("not a list")
+	if err == nil {
+		Log.Fail(t, "Expected error for a non-pointer list")
+	}
+}
+
 func testAsListWithoutRegistration(t *testing.T) {
 	res, _ := CreateResources(25000, 2, ifs.Info_Level)
 	res.Registry().Register(&testtypes.TestProto{})
@@ -298,3 +372,234 @@ func TestAppendBugFix(t *testing.T) {
 		Log.Fail(t, "Append method has a bug. Expected", expectedCount, "elements, got:", finalCount)
 	}
 }
+
+// TestSerializeModes verifies that the notification, replica and filter mode
+// flags survive serialization, and that payloads without the mode section
+// still deserialize.
+func TestSerializeModes(t *testing.T) {
+	notify := object.NewNotify("changed")
+	data, err := notify.Serialize()
+	if err != nil {
+		Log.Fail(t, "Serialize failed:", err)
+		return
+	}
+	deserialized := &object.Elements{}
+	err = deserialized.Deserialize(data, globals.Registry())
+	if err != nil {
+		Log.Fail(t, "Deserialize failed:", err)
+		return
+	}
+	if !deserialized.Notification() || deserialized.IsReplica() {
+		Log.Fail(t, "Expected notification flag after deserialization")
+	}
+
+	replica := object.NewReplicaRequest(object.New(nil, "data"), 3)
+	data, err = replica.Serialize()
+	if err != nil {
+		Log.Fail(t, "Serialize failed:", err)
+		return
+	}
+	deserialized = &object.Elements{}
+	err = deserialized.Deserialize(data, globals.Registry())
+	if err != nil {
+		Log.Fail(t, "Deserialize failed:", err)
+		return
+	}
+	if !deserialized.IsReplica() || deserialized.Replica() != 3 || deserialized.Notification() {
+		Log.Fail(t, "Expected replica 3 after deserialization")
+	}
+	if deserialized.IsFilterMode() != replica.IsFilterMode() {
+		Log.Fail(t, "Filter mode mismatch after deserialization")
+	}
+
+	// A payload as written before the mode section existed
+	legacy := object.NewEncode()
+	legacy.Add(1)
+	legacy.Add("data")
+	legacy.Add(nil)
+	legacy.Add("")
+	legacy.Add(nil)
+	legacy.Add(nil)
+	deserialized = &object.Elements{}
+	err = deserialized.Deserialize(legacy.Data(), globals.Registry())
+	if err != nil {
+		Log.Fail(t, "Deserialize of legacy payload failed:", err)
+		return
+	}
+	if deserialized.IsReplica() || deserialized.Element() != "data" {
+		Log.Fail(t, "Unexpected legacy payload result")
+	}
+}
+
+// TestHeaders verifies that headers are serialized and preserved by
+// NewReplicaRequest, and that unsupported values are rejected.
+func TestHeaders(t *testing.T) {
+	elems := object.New(nil, "data").(*object.Elements)
+	elems.SetHeader(object.HeaderTraceID, "trace-1")
+	elems.SetHeader(object.HeaderDeadline, int64(1700000000000000000))
+	elems.SetHeader("retries", int32(2))
+	if elems.SetHeader("bad", []string{"x"}) == nil {
+		Log.Fail(t, "Expected error for a non primitive header")
+	}
+
+	replica := object.NewReplicaRequest(elems, 1)
+	data, err := replica.Serialize()
+	if err != nil {
+		Log.Fail(t, "Serialize failed:", err)
+		return
+	}
+	deserialized := &object.Elements{}
+	err = deserialized.Deserialize(data, globals.Registry())
+	if err != nil {
+		Log.Fail(t, "Deserialize failed:", err)
+		return
+	}
+	if deserialized.HeaderString(object.HeaderTraceID) != "trace-1" ||
+		deserialized.HeaderInt64(object.HeaderDeadline) != 1700000000000000000 {
+		Log.Fail(t, "Header mismatch after deserialization:", deserialized.Headers())
+	}
+	retries, ok := deserialized.Header("retries")
+	if !ok || retries.(int32) != 2 {
+		Log.Fail(t, "Expected int32 header after deserialization")
+	}
+	if _, ok = deserialized.Header("bad"); ok {
+		Log.Fail(t, "Unexpected header after deserialization")
+	}
+}
+
+// TestClone verifies that shallow and deep clones copy every field and that
+// mutating a clone does not affect the original.
+func TestClone(t *testing.T) {
+	original := object.NewQueryResult([]*testtypes.TestProto{CreateTestModelInstance(1), CreateTestModelInstance(2)},
+		&l8api.L8MetaData{KeyCount: &l8api.L8Count{Counts: map[string]float64{"Total": 2}}}).(*object.Elements)
+	original.SetHeader(object.HeaderTenantID, "tenant-1")
+
+	shallow := original.Clone(false)
+	shallow.Add(CreateTestModelInstance(3), 2, errors.New("third"))
+	if len(original.Elements()) != 2 || len(shallow.Elements()) != 3 {
+		Log.Fail(t, "Adding to a shallow clone changed the original")
+	}
+	if shallow.Metadata() != original.Metadata() || shallow.HeaderString(object.HeaderTenantID) != "tenant-1" {
+		Log.Fail(t, "Shallow clone did not copy metadata or headers")
+	}
+	if shallow.Keys()[1] != 1 {
+		Log.Fail(t, "Shallow clone did not copy keys")
+	}
+
+	deep := original.Clone(true)
+	deep.Elements()[0].(*testtypes.TestProto).MyString = "changed"
+	deep.Metadata().KeyCount.Counts["Total"] = 5
+	if original.Elements()[0].(*testtypes.TestProto).MyString == "changed" {
+		Log.Fail(t, "Mutating a deep clone changed the original element")
+	}
+	if original.Metadata().KeyCount.Counts["Total"] != 2 {
+		Log.Fail(t, "Mutating a deep clone changed the original metadata")
+	}
+
+	failed := &object.Elements{}
+	failed.Add(nil, "a", object.NewElementError(7, "failed").WithDetail("node", "n1").
+		WithCause(object.NewElementError(8, "cause")))
+	deepErr := failed.Clone(true).Errors()[0].(*object.ElementError)
+	deepErr.Details["node"] = "n2"
+	deepErr.Cause.(*object.ElementError).Message = "changed"
+	originalErr := failed.Errors()[0].(*object.ElementError)
+	if originalErr.Details["node"] != "n1" || originalErr.Cause.Error() != "cause" {
+		Log.Fail(t, "Mutating a deep clone changed the original error")
+	}
+
+	replica := object.NewReplicaRequest(original, 2).(*object.Elements)
+	replica.Add(CreateTestModelInstance(4), 3, nil)
+	if len(original.Elements()) != 2 || replica.Metadata() == nil || !replica.IsReplica() {
+		Log.Fail(t, "Replica request shares elements or dropped metadata")
+	}
+}
+
+// TestMerge verifies appending and upserting with keys, errors and
+// metadata counts preserved.
+func TestMerge(t *testing.T) {
+	shard1 := object.NewQueryResult(map[string]string{"a": "a1", "b": "b1"},
+		&l8api.L8MetaData{KeyCount: &l8api.L8Count{Counts: map[string]float64{"Total": 2}}}).(*object.Elements)
+	shard2 := &object.Elements{}
+	shard2.Add("b2", "b", nil)
+	shard2.Add("c2", "c", errors.New("c failed"))
+	shard2.Add("n2", nil, nil)
+
+	appended := shard1.Clone(false)
+	appended.Merge(shard2, object.MergeAppend)
+	if len(appended.Elements()) != 5 || appended.Keys()[3] != "c" || appended.Errors()[3] == nil {
+		Log.Fail(t, "Append merge lost keys or errors")
+	}
+	if appended.Metadata().KeyCount.Counts["Total"] != 5 {
+		Log.Fail(t, "Expected merged total of 5, got", appended.Metadata().KeyCount.Counts["Total"])
+	}
+	if shard1.Metadata().KeyCount.Counts["Total"] != 2 {
+		Log.Fail(t, "Merge modified the original metadata")
+	}
+
+	upserted := shard1.Clone(false)
+	upserted.Merge(shard2, object.MergeUpsert)
+	if len(upserted.Elements()) != 4 {
+		Log.Fail(t, "Expected 4 elements after upsert, got", len(upserted.Elements()))
+	}
+	for i, key := range upserted.Keys() {
+		if key == "b" && upserted.Elements()[i] != "b2" {
+			Log.Fail(t, "Expected b to be replaced by b2")
+		}
+	}
+	if upserted.Metadata().KeyCount.Counts["Total"] != 4 {
+		Log.Fail(t, "Expected upserted total of 4, got", upserted.Metadata().KeyCount.Counts["Total"])
+	}
+
+	// A duplicate key with metadata on both sides, including derived counts
+	shard3 := object.NewQueryResult(map[string]string{"a": "a3"},
+		&l8api.L8MetaData{KeyCount: &l8api.L8Count{Counts: map[string]float64{"Total": 1,
+			object.CountPage: 1, object.CountVersion: 7, "*/max(MyInt32)": 9, "*/sum(MyInt32)": 4}}})
+	upserted = object.NewQueryResult(map[string]string{"a": "a1", "b": "b1"},
+		&l8api.L8MetaData{KeyCount: &l8api.L8Count{Counts: map[string]float64{"Total": 2,
+			object.CountPage: 1, object.CountVersion: 3, "*/max(MyInt32)": 5, "*/sum(MyInt32)": 6}}}).(*object.Elements)
+	upserted.Merge(shard3, object.MergeUpsert)
+	counts := upserted.Metadata().KeyCount.Counts
+	if counts["Total"] != 2 || counts[object.CountPage] != 1 || counts[object.CountVersion] != 7 ||
+		counts["*/max(MyInt32)"] != 9 || counts["*/sum(MyInt32)"] != 10 {
+		Log.Fail(t, "Unexpected merged counts", counts)
+	}
+}
+
+// TestKeyIndex verifies lookups by slice index and map keys, removal, and
+// lookups after deserialization.
+func TestKeyIndex(t *testing.T) {
+	bySlice := object.New(nil, []string{"zero", "one", "two"}).(*object.Elements)
+	value, ok := bySlice.ByKey(1)
+	if !ok || value != "one" || bySlice.Len() != 3 {
+		Log.Fail(t, "Expected lookup by slice index")
+	}
+
+	byMap := object.New(nil, map[string]int32{"a": 1, "b": 2, "c": 3}).(*object.Elements)
+	byMap.Add(int32(4), "d", errors.New("d failed"))
+	if !byMap.HasKey("d") || byMap.ErrorByKey("d") == nil || byMap.HasKey("e") {
+		Log.Fail(t, "Expected lookup of an added key")
+	}
+	if !byMap.RemoveKey("b") || byMap.HasKey("b") || byMap.Len() != 3 {
+		Log.Fail(t, "Expected b to be removed")
+	}
+	value, ok = byMap.ByKey("c")
+	if !ok || value.(int32) != 3 {
+		Log.Fail(t, "Expected lookup after removal")
+	}
+
+	data, err := byMap.Serialize()
+	if err != nil {
+		Log.Fail(t, "Serialize failed:", err)
+		return
+	}
+	deserialized := &object.Elements{}
+	err = deserialized.Deserialize(data, globals.Registry())
+	if err != nil {
+		Log.Fail(t, "Deserialize failed:", err)
+		return
+	}
+	value, ok = deserialized.ByKey("a")
+	if !ok || value.(int32) != 1 || deserialized.ErrorByKey("d") == nil {
+		Log.Fail(t, "Expected lookup after deserialization")
+	}
+}
@@ -0,0 +1,101 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	. "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/testtypes"
+)
+
+// testQuery parses an L8QL query and returns its ifs.IQuery.
+func testQuery(t *testing.T, gsql string, res ifs.IResources) ifs.IQuery {
+	elems, err := object.NewQuery(gsql, res)
+	if err != nil {
+		t.Fatalf("Failed to parse query: %v", err)
+	}
+	query, err := elems.Query(res)
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	return query
+}
+
+// testSnapshot returns a container with test models 1 to size, keyed by their string.
+func testSnapshot(size int) *object.Elements {
+	elems := &object.Elements{}
+	for i := 1; i <= size; i++ {
+		item := CreateTestModelInstance(i)
+		elems.Add(item, item.MyString, nil)
+	}
+	return elems
+}
+
+// TestFilter verifies that only matching elements are kept with their keys
+// and that the total count is updated.
+func TestFilter(t *testing.T) {
+	res, _ := CreateResources(25000, 2, ifs.Info_Level)
+	res.Registry().Register(&testtypes.TestProto{})
+	query := testQuery(t, "select * from TestProto where MyString=string-3", res)
+
+	result := testSnapshot(5).Filter(query)
+	if result.Len() != 1 {
+		t.Fatalf("Expected 1 element, got %d", result.Len())
+	}
+	if result.Keys()[0] != "string-3" {
+		t.Errorf("Unexpected key %v", result.Keys()[0])
+	}
+	if result.Metadata().KeyCount.Counts["Total"] != 1 {
+		t.Errorf("Expected total 1, got %v", result.Metadata().KeyCount.Counts["Total"])
+	}
+
+	all := testSnapshot(5).Filter(nil)
+	if all.Len() != 5 || all.Metadata().KeyCount.Counts["Total"] != 5 {
+		t.Errorf("Expected a nil query to match all elements")
+	}
+}
+
+// TestApply verifies sort, limit and page, and that the total count is the
+// number of matching elements before paging.
+func TestApply(t *testing.T) {
+	res, _ := CreateResources(25000, 2, ifs.Info_Level)
+	res.Registry().Register(&testtypes.TestProto{})
+	query := testQuery(t, "select * from TestProto sort-by MyInt32 descending limit 2 page 1", res)
+
+	snapshot := testSnapshot(5)
+	result := snapshot.Apply(query)
+	if result.Len() != 2 {
+		t.Fatalf("Expected 2 elements, got %d", result.Len())
+	}
+	first := result.Elements()[0].(*testtypes.TestProto)
+	second := result.Elements()[1].(*testtypes.TestProto)
+	if first.MyInt32 != 3 || second.MyInt32 != 2 {
+		t.Errorf("Unexpected page: %d, %d", first.MyInt32, second.MyInt32)
+	}
+	if result.Metadata().KeyCount.Counts["Total"] != 5 {
+		t.Errorf("Expected total 5, got %v", result.Metadata().KeyCount.Counts["Total"])
+	}
+	if snapshot.Len() != 5 || snapshot.Elements()[0].(*testtypes.TestProto).MyInt32 != 1 {
+		t.Errorf("Apply modified the snapshot")
+	}
+
+	last := snapshot.Apply(testQuery(t, "select * from TestProto sort-by MyString limit 2 page 2", res))
+	if last.Len() != 1 || last.Keys()[0] != "string-5" {
+		t.Errorf("Expected the last page to hold string-5, got %v", last.Keys())
+	}
+}
@@ -0,0 +1,113 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	. "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/testtypes"
+)
+
+// TestGenerics_ElementsAs verifies typed slices and type mismatch errors.
+func TestGenerics_ElementsAs(t *testing.T) {
+	elems := object.New(nil, []*testtypes.TestProto{CreateTestModelInstance(1), nil, CreateTestModelInstance(2)})
+	protos, err := object.ElementsAs[*testtypes.TestProto](elems)
+	if err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+	if len(protos) != 3 || protos[1] != nil || protos[2].MyString != "string-2" {
+		t.Errorf("Unexpected typed slice: %v", protos)
+	}
+
+	_, err = object.ElementsAs[string](elems)
+	if err == nil {
+		t.Error("Expected a type mismatch error")
+	}
+}
+
+// TestGenerics_MapAs verifies typed maps and key type mismatch errors.
+func TestGenerics_MapAs(t *testing.T) {
+	val := map[string]int32{"a": 1, "b": 2}
+	result, err := object.MapAs[string, int32](object.New(nil, val))
+	if err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+	if len(result) != 2 || result["a"] != 1 || result["b"] != 2 {
+		t.Errorf("Unexpected typed map: %v", result)
+	}
+
+	_, err = object.MapAs[int, int32](object.New(nil, val))
+	if err == nil {
+		t.Error("Expected a key type mismatch error")
+	}
+}
+
+// TestGenerics_Iterators verifies the key/element and element/error iterators.
+func TestGenerics_Iterators(t *testing.T) {
+	elems := &object.Elements{}
+	elems.Add("a", "key-a", nil)
+	elems.Add("b", "key-b", errors.New("b failed"))
+
+	count := 0
+	for key, elem := range object.KeyValues(elems) {
+		if key.(string) != "key-"+elem.(string) {
+			t.Errorf("Key %v does not match element %v", key, elem)
+		}
+		count++
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 pairs, got %d", count)
+	}
+
+	failed := 0
+	for _, err := range object.ValuesErrors(elems) {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Errorf("Expected 1 error, got %d", failed)
+	}
+}
+
+// TestGenerics_TypedIterators verifies the typed iterator and MapAs, with
+// reported type mismatches.
+func TestGenerics_TypedIterators(t *testing.T) {
+	elems := &object.Elements{}
+	elems.Add("a", "key-a", nil)
+	elems.Add(2, "key-2", nil)
+	elems.Add(nil, "key-nil", nil)
+	elems.Add("c", 3, nil)
+
+	_, err := object.MapAs[string, string](elems)
+	if err == nil {
+		t.Error("Expected a type mismatch error from MapAs")
+	}
+
+	var values []string
+	mismatches := 0
+	for value, err := range object.ValuesErrorsAs[string](elems) {
+		if err != nil {
+			mismatches++
+		}
+		values = append(values, value)
+	}
+	if len(values) != 4 || values[0] != "a" || values[3] != "c" || mismatches != 1 {
+		t.Errorf("Unexpected typed values %v with %d mismatches", values, mismatches)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/testtypes"
+)
+
+// testStats returns right side elements for models 1 and 3, keyed by the
+// model string, the one of model 3 with an error.
+func testStats() *object.Elements {
+	stats := &object.Elements{}
+	stats.Add(&testtypes.TestProtoSub{MyString: "string-1", MyInt64: 100}, "string-1", nil)
+	stats.Add(&testtypes.TestProtoSub{MyString: "string-3", MyInt64: 300}, "string-3",
+		object.NewElementError(42, "stale").WithDetail("age", "5m"))
+	return stats
+}
+
+// TestJoin_Inner verifies an inner join on keys carries the right errors,
+// and that the pairs and errors survive serialization.
+func TestJoin_Inner(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	globals.Registry().Register(&testtypes.TestProtoSub{})
+	result := testSnapshot(4).Join(testStats(), "", "", object.JoinInner)
+	if result.Len() != 2 {
+		t.Fatalf("Expected 2 pairs, got %d", result.Len())
+	}
+	pair := result.Elements()[1].(*object.JoinPair)
+	if pair.Left.(*testtypes.TestProto).MyInt32 != 3 || pair.Right.(*testtypes.TestProtoSub).MyInt64 != 300 {
+		t.Errorf("Unexpected pair %v", pair)
+	}
+	if result.Keys()[1] != "string-3" || result.Errors()[1] == nil || result.Errors()[0] != nil {
+		t.Errorf("Unexpected keys or errors %v %v", result.Keys(), result.Errors())
+	}
+
+	data, err := result.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	restored := &object.Elements{}
+	err = restored.Deserialize(data, globals.Registry())
+	if err != nil {
+		t.Fatalf("Failed to deserialize: %v", err)
+	}
+	pair = restored.Elements()[1].(*object.JoinPair)
+	if pair.Left.(*testtypes.TestProto).MyInt32 != 3 || pair.Right.(*testtypes.TestProtoSub).MyInt64 != 300 {
+		t.Errorf("Unexpected pair after deserialization %v", pair)
+	}
+	ee, ok := restored.Errors()[1].(*object.ElementError)
+	if !ok || ee.Code != 42 || ee.Details["age"] != "5m" {
+		t.Errorf("Expected the right error with its code, got %v", restored.Errors()[1])
+	}
+}
+
+// TestJoin_Left verifies a left join on property paths with a merge function.
+func TestJoin_Left(t *testing.T) {
+	merge := func(left, right interface{}) interface{} {
+		model := left.(*testtypes.TestProto)
+		if right != nil {
+			model.MyInt64 = right.(*testtypes.TestProtoSub).MyInt64
+		}
+		return model
+	}
+	result := testSnapshot(4).JoinWith(testStats(), "testproto.mystring", "MyString", object.JoinLeft, merge)
+	if result.Len() != 4 {
+		t.Fatalf("Expected 4 elements, got %d", result.Len())
+	}
+	for i, elem := range result.Elements() {
+		model := elem.(*testtypes.TestProto)
+		expected := int64((i + 1) * 10)
+		if i == 0 || i == 2 {
+			expected = int64((i + 1) * 100)
+		}
+		if model.MyInt64 != expected {
+			t.Errorf("Element %d: expected %d, got %d", i, expected, model.MyInt64)
+		}
+	}
+	if result.Metadata().KeyCount.Counts["Total"] != 4 {
+		t.Errorf("Unexpected total %v", result.Metadata().KeyCount.Counts["Total"])
+	}
+}
+
+// TestJoin_Anti verifies that an anti join keeps only unmatched elements.
+func TestJoin_Anti(t *testing.T) {
+	result := testSnapshot(4).Join(testStats(), "MyString", "MyString", object.JoinAnti)
+	if result.Len() != 2 || result.Keys()[0] != "string-2" || result.Keys()[1] != "string-4" {
+		t.Errorf("Unexpected anti join %v", result.Keys())
+	}
+	if _, ok := result.Elements()[0].(*testtypes.TestProto); !ok {
+		t.Errorf("Expected the left elements")
+	}
+}
@@ -0,0 +1,136 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/testtypes"
+)
+
+// TestMerkle_Equal verifies that equal contents in any order give equal trees.
+func TestMerkle_Equal(t *testing.T) {
+	a := testSnapshot(30)
+	b := &object.Elements{}
+	for i := a.Len() - 1; i >= 0; i-- {
+		b.Add(a.Elements()[i], a.Keys()[i], nil)
+	}
+	ta, err := object.NewMerkleTree(a, 6)
+	if err != nil {
+		t.Fatalf("Failed to build tree: %v", err)
+	}
+	tb, _ := object.NewMerkleTree(b, 6)
+	if !bytes.Equal(ta.Root(), tb.Root()) {
+		t.Errorf("Expected equal roots")
+	}
+	ranges, err := ta.Compare(tb)
+	if err != nil || len(ranges) != 0 {
+		t.Errorf("Expected no differences, got %v %v", ranges, err)
+	}
+}
+
+// TestMerkle_Compare verifies that only the ranges of changed, added and
+// removed keys differ, and that they select the divergent keys.
+func TestMerkle_Compare(t *testing.T) {
+	a := testSnapshot(30)
+	b := testSnapshot(31)
+	b.RemoveKey("string-4")
+	changed, _ := b.ByKey("string-17")
+	changed.(*testtypes.TestProto).MyFloat64 = 0
+
+	ta, _ := object.NewMerkleTree(a, 8)
+	tb, _ := object.NewMerkleTree(b, 8)
+	if bytes.Equal(ta.Root(), tb.Root()) {
+		t.Fatalf("Expected different roots")
+	}
+	ranges, err := ta.Compare(tb)
+	if err != nil {
+		t.Fatalf("Failed to compare: %v", err)
+	}
+	if len(ranges) == 0 || len(ranges) > 3 {
+		t.Fatalf("Expected 1 to 3 ranges, got %d", len(ranges))
+	}
+	repair := object.KeysInRanges(b, ranges)
+	if !repair.HasKey("string-17") || !repair.HasKey("string-31") || repair.Len() > 6 {
+		t.Errorf("Unexpected repair keys %v", repair.Keys())
+	}
+	if !object.KeysInRanges(a, ranges).HasKey("string-4") {
+		t.Errorf("Expected the removed key in a differing range")
+	}
+
+	shallow, _ := object.NewMerkleTree(b, 4)
+	if _, err = ta.Compare(shallow); err == nil {
+		t.Errorf("Expected an error for trees of different depths")
+	}
+}
+
+// TestMerkle_Serialize verifies that a tree survives serialization.
+func TestMerkle_Serialize(t *testing.T) {
+	tree, _ := object.NewMerkleTree(testSnapshot(10), 5)
+	data, err := tree.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	result := &object.MerkleTree{}
+	err = result.Deserialize(data)
+	if err != nil {
+		t.Fatalf("Failed to deserialize: %v", err)
+	}
+	if result.Depth() != 5 || !bytes.Equal(result.Root(), tree.Root()) ||
+		!bytes.Equal(result.Hash(5, 7), tree.Hash(5, 7)) {
+		t.Errorf("Deserialized tree does not match")
+	}
+	ranges, _ := result.Compare(tree)
+	if len(ranges) != 0 {
+		t.Errorf("Expected no differences")
+	}
+	if r := tree.Range(1, 1); r.Start != 1<<63 || r.End != ^uint64(0) {
+		t.Errorf("Unexpected range %v", r)
+	}
+	if (&object.MerkleTree{}).Deserialize(data[:len(data)-1]) == nil {
+		t.Errorf("Expected an error for a truncated tree")
+	}
+	if len(data) != 8+32*32 {
+		t.Errorf("Expected only the leaves to be serialized, got %d bytes", len(data))
+	}
+	if _, err = object.NewMerkleTree(testSnapshot(1), object.MaxMerkleDepth+1); err == nil {
+		t.Errorf("Expected an error for a depth above the maximum")
+	}
+}
+
+// TestMerkle_MapValues verifies that map values hash the same whatever the
+// iteration order of their entries.
+func TestMerkle_MapValues(t *testing.T) {
+	value := make(map[string]int32)
+	for i := 0; i < 50; i++ {
+		value["key-"+strconv.Itoa(i)] = int32(i)
+	}
+	elems := &object.Elements{}
+	elems.Add(value, "a", nil)
+	elems.Add([]map[string]int32{value, value}, "b", nil)
+	first, err := object.NewMerkleTree(elems, 2)
+	if err != nil {
+		t.Fatalf("Failed to build tree: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		tree, _ := object.NewMerkleTree(elems, 2)
+		if !bytes.Equal(tree.Root(), first.Root()) {
+			t.Fatalf("Map values hashed differently on attempt %d", i)
+		}
+	}
+}
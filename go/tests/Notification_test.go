@@ -0,0 +1,124 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	. "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/testtypes"
+)
+
+// TestNotification_Serialize verifies that the source, sequence and change
+// types survive serialization.
+func TestNotification_Serialize(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	sequencer := object.NewSequencer("node-1")
+	sequencer.Stamp(&object.Elements{})
+
+	elems := &object.Elements{}
+	elems.AddChange(CreateTestModelInstance(1), "string-1", object.ChangeAdded)
+	elems.AddChange(CreateTestModelInstance(2), "string-2", object.ChangeChanged)
+	elems.AddChange(nil, "string-3", object.ChangeRemoved)
+	if sequencer.Stamp(elems) != 2 {
+		t.Fatalf("Expected sequence 2")
+	}
+
+	data, err := elems.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	result := &object.Elements{}
+	err = result.Deserialize(data, globals.Registry())
+	if err != nil {
+		t.Fatalf("Failed to deserialize: %v", err)
+	}
+	if !result.Notification() || result.Source() != "node-1" || result.Sequence() != 2 {
+		t.Errorf("Unexpected notification %v %s %d", result.Notification(), result.Source(), result.Sequence())
+	}
+	expected := []object.ChangeType{object.ChangeAdded, object.ChangeChanged, object.ChangeRemoved}
+	if !reflect.DeepEqual(result.ChangeTypes(), expected) {
+		t.Errorf("Unexpected change types %v", result.ChangeTypes())
+	}
+
+	plain := &object.Elements{}
+	data, _ = object.New(nil, "value").Serialize()
+	plain.Deserialize(data, globals.Registry())
+	if plain.Sequence() != 0 || plain.ChangeTypes()[0] != 0 {
+		t.Errorf("Expected no sequence or change type")
+	}
+}
+
+// TestNotification_Tracker verifies gap, late and duplicate detection.
+func TestNotification_Tracker(t *testing.T) {
+	tracker := object.NewSequenceTracker()
+	steps := []struct {
+		source   string
+		sequence uint64
+		status   object.SequenceStatus
+	}{
+		{"a", 5, object.SequenceInOrder},
+		{"a", 6, object.SequenceInOrder},
+		{"b", 1, object.SequenceInOrder},
+		{"a", 9, object.SequenceGap},
+		{"a", 8, object.SequenceLate},
+		{"a", 8, object.SequenceDuplicate},
+		{"a", 6, object.SequenceDuplicate},
+		{"a", 10, object.SequenceInOrder},
+		{"a", 0, object.SequenceUnsequenced},
+	}
+	for i, step := range steps {
+		status := tracker.Track(step.source, step.sequence)
+		if status != step.status {
+			t.Errorf("Step %d: expected %d, got %d", i, step.status, status)
+		}
+	}
+	if !reflect.DeepEqual(tracker.Missing("a"), []uint64{7}) {
+		t.Errorf("Unexpected missing %v", tracker.Missing("a"))
+	}
+	tracker.Reset("a")
+	if tracker.Missing("a") != nil {
+		t.Errorf("Expected the source to be forgotten")
+	}
+
+	elems := &object.Elements{}
+	elems.SetSequence("b", 2)
+	if tracker.TrackElements(elems) != object.SequenceInOrder {
+		t.Errorf("Expected the next notification of b to be in order")
+	}
+}
+
+// TestNotification_TrackerCap verifies that the missing sequence numbers of
+// a source are capped across gaps, forgetting the oldest first.
+func TestNotification_TrackerCap(t *testing.T) {
+	tracker := object.NewSequenceTracker()
+	tracker.Track("a", 1)
+	sequence := uint64(1)
+	for i := 0; i < 10; i++ {
+		sequence += 4001
+		if tracker.Track("a", sequence) != object.SequenceGap {
+			t.Fatalf("Expected a gap at %d", sequence)
+		}
+	}
+	missing := tracker.Missing("a")
+	if len(missing) != 16384 || missing[len(missing)-1] != sequence-1 {
+		t.Fatalf("Expected the 16384 most recent missing numbers, got %d", len(missing))
+	}
+	if tracker.Track("a", 2) != object.SequenceDuplicate {
+		t.Errorf("Expected a forgotten missing number to be reported as a duplicate")
+	}
+}
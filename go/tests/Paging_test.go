@@ -0,0 +1,114 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8types/go/types/l8api"
+)
+
+// TestPaging_Page verifies the page content, the page information and that
+// it survives serialization.
+func TestPaging_Page(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	query := &l8api.L8Query{RootType: "testproto", Limit: 2, Page: 1}
+	page := testSnapshot(5).Page(query)
+	if page.Len() != 2 || page.Keys()[0] != "string-3" || page.Keys()[1] != "string-4" {
+		t.Fatalf("Unexpected page %v", page.Keys())
+	}
+
+	data, err := page.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	result := &object.Elements{}
+	err = result.Deserialize(data, globals.Registry())
+	if err != nil {
+		t.Fatalf("Failed to deserialize: %v", err)
+	}
+	info := result.PageInfo()
+	if info == nil || info.Page != 1 || info.PageSize != 2 || info.Offset != 2 || info.Total != 5 || !info.HasMore {
+		t.Fatalf("Unexpected page info %+v", info)
+	}
+
+	next, offset, err := object.ParseContinuationToken(info.Continuation)
+	if err != nil {
+		t.Fatalf("Failed to parse token: %v", err)
+	}
+	if offset != 4 || next.Page != 2 || next.Limit != 2 || next.RootType != "testproto" {
+		t.Errorf("Unexpected token content %d %v", offset, next)
+	}
+	last := testSnapshot(5).Page(next)
+	if last.Len() != 1 || last.PageInfo().HasMore || last.PageInfo().Continuation != "" {
+		t.Errorf("Expected a last page without more elements")
+	}
+}
+
+// TestPaging_PageCopy verifies that merging into a page leaves the source
+// elements unchanged.
+func TestPaging_PageCopy(t *testing.T) {
+	source := testSnapshot(5)
+	page := source.Page(&l8api.L8Query{Limit: 2, Page: 1})
+	update := &object.Elements{}
+	update.Add("replaced", page.Keys()[0], nil)
+	page.Merge(update, object.MergeUpsert)
+
+	if page.Elements()[0] != "replaced" {
+		t.Fatalf("Expected the page element to be replaced, got %v", page.Elements()[0])
+	}
+	if source.Elements()[2] == "replaced" {
+		t.Errorf("Expected the source element to be unchanged")
+	}
+}
+
+// TestPaging_Pages verifies splitting into all the pages.
+func TestPaging_Pages(t *testing.T) {
+	pages := testSnapshot(5).Pages(&l8api.L8Query{Limit: 2})
+	if len(pages) != 3 || pages[2].Len() != 1 || pages[2].PageInfo().Page != 2 {
+		t.Fatalf("Unexpected pages %d", len(pages))
+	}
+	all := testSnapshot(5).Pages(nil)
+	if len(all) != 1 || all[0].Len() != 5 || all[0].PageInfo().HasMore {
+		t.Errorf("Expected a single page without a limit")
+	}
+	if (&object.Elements{}).Page(&l8api.L8Query{Limit: 2, Page: 3}).Len() != 0 {
+		t.Errorf("Expected an empty page past the end")
+	}
+	first := testSnapshot(5).Page(&l8api.L8Query{Limit: 2, Page: -1})
+	if first.Len() != 2 || first.PageInfo().Page != 0 || first.PageInfo().Offset != 0 {
+		t.Errorf("Expected the first page for a negative page, got %+v", first.PageInfo())
+	}
+	if testSnapshot(5).Page(&l8api.L8Query{Limit: math.MaxInt32, Page: math.MaxInt32}).Len() != 0 {
+		t.Errorf("Expected an empty page for a page far past the end")
+	}
+}
+
+// TestPaging_InvalidToken verifies that malformed tokens are rejected.
+func TestPaging_InvalidToken(t *testing.T) {
+	for _, token := range []string{"", "!!!", "AA", "AYCAgICAgICAgICAAQ"} {
+		_, _, err := object.ParseContinuationToken(token)
+		if err != object.ErrInvalidContinuation {
+			t.Errorf("Expected invalid token error for %q, got %v", token, err)
+		}
+	}
+	_, _, err := object.ParseContinuationToken(object.NewContinuationToken(nil, math.MaxInt32+1))
+	if err != object.ErrInvalidContinuation {
+		t.Errorf("Expected invalid token error for a large offset, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/testtypes"
+)
+
+// TestPartition verifies that every key goes to a single, stable part, that
+// parts carry the headers and replica set, and that Gather restores the order.
+func TestPartition(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	elems := testSnapshot(50)
+	elems.SetHeader(object.HeaderTenantID, "tenant-1")
+
+	parts, err := object.Partition(elems, 4, nil)
+	if err != nil {
+		t.Fatalf("Failed to partition: %v", err)
+	}
+	again, _ := object.Partition(elems, 4, nil)
+	total := 0
+	responses := make([]ifs.IElements, len(parts))
+	for i, part := range parts {
+		total += part.Len()
+		if part.Len() == 0 {
+			t.Errorf("Part %d is empty", i)
+		}
+		if !reflect.DeepEqual(part.Keys(), again[i].Keys()) {
+			t.Errorf("Part %d is not stable", i)
+		}
+		if part.HeaderString(object.HeaderTenantID) != "tenant-1" || !part.Replicas().Has(byte(i)) {
+			t.Errorf("Part %d lost its headers or replica", i)
+		}
+		data, err := part.Serialize()
+		if err != nil {
+			t.Fatalf("Failed to serialize: %v", err)
+		}
+		response := &object.Elements{}
+		err = response.Deserialize(data, globals.Registry())
+		if err != nil {
+			t.Fatalf("Failed to deserialize: %v", err)
+		}
+		if response.Replica() != byte(i) || response.Replicas() != object.NewReplicaSet(byte(i)) {
+			t.Errorf("Part %d lost its replica set", i)
+		}
+		responses[len(parts)-1-i] = response
+	}
+	if total != 50 {
+		t.Fatalf("Expected 50 elements in the parts, got %d", total)
+	}
+
+	gathered := object.Gather(elems, responses...)
+	if !reflect.DeepEqual(gathered.Keys(), elems.Keys()) {
+		t.Errorf("Gather did not restore the order %v", gathered.Keys())
+	}
+	if gathered.Metadata().KeyCount.Counts["Total"] != 50 {
+		t.Errorf("Unexpected total %v", gathered.Metadata().KeyCount.Counts["Total"])
+	}
+
+	moved := 0
+	more, _ := object.Partition(elems, 5, nil)
+	for i, part := range parts {
+		for _, key := range part.Keys() {
+			if !more[i].HasKey(key) {
+				moved++
+			}
+		}
+	}
+	if moved > 20 {
+		t.Errorf("Expected few keys to move when adding a replica, %d moved", moved)
+	}
+}
+
+// TestReplicaSet verifies the replica set operations.
+func TestReplicaSet(t *testing.T) {
+	set := object.NewReplicaSet(1, 5, 63, 64)
+	if !set.Has(1) || !set.Has(63) || set.Has(64) || set.Has(2) {
+		t.Errorf("Unexpected set %b", set)
+	}
+	if !reflect.DeepEqual(set.Replicas(), []byte{1, 5, 63}) {
+		t.Errorf("Unexpected replicas %v", set.Replicas())
+	}
+	elems := &object.Elements{}
+	elems.SetReplicas(object.NewReplicaSet(3, 7))
+	if !elems.IsReplica() || elems.Replica() != 3 || !elems.Replicas().Has(7) {
+		t.Errorf("Unexpected replica request")
+	}
+	if object.NewReplicaRequest(testSnapshot(1), 2).(*object.Elements).Replicas() != object.NewReplicaSet(2) {
+		t.Errorf("Expected the replica number as a set")
+	}
+	if object.NewReplicaRequest(elems, 2).(*object.Elements).Replicas() != object.NewReplicaSet(2) {
+		t.Errorf("Expected the replica set of the source to be replaced")
+	}
+	if _, err := object.Partition(elems, 0, nil); err == nil {
+		t.Errorf("Expected an error for zero parts")
+	}
+}
+
+// TestGather_Changes verifies that gathering keeps the change types of the
+// parts and sets the total from the gathered elements.
+func TestGather_Changes(t *testing.T) {
+	elems := &object.Elements{}
+	elems.AddChange("a1", "a", object.ChangeAdded)
+	elems.AddChange("b1", "b", object.ChangeRemoved)
+	elems.AddChange("c1", "c", object.ChangeChanged)
+	parts, err := object.Partition(elems, 2, nil)
+	if err != nil {
+		t.Fatalf("Failed to partition: %v", err)
+	}
+	responses := make([]ifs.IElements, len(parts))
+	for i, part := range parts {
+		responses[i] = part
+	}
+	gathered := object.Gather(elems, responses...)
+	if !reflect.DeepEqual(gathered.ChangeTypes(), elems.ChangeTypes()) {
+		t.Errorf("Gather lost the change types %v", gathered.ChangeTypes())
+	}
+	if gathered.Metadata().KeyCount.Counts["Total"] != 3 {
+		t.Errorf("Unexpected total %v", gathered.Metadata().KeyCount.Counts["Total"])
+	}
+}
@@ -0,0 +1,109 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8types/go/types/l8api"
+)
+
+// testReplicas returns the responses of three replicas for models 1 to 3.
+// Replica 2 has a newer model 2, replica 3 misses model 3 and fails model 1.
+func testReplicas() []ifs.IElements {
+	r1 := testSnapshot(3)
+	r2 := testSnapshot(3)
+	updated, _ := r2.ByKey("string-2")
+	updated.(*testtypes.TestProto).MyInt64 = 99
+	r3 := testSnapshot(2)
+	r3.RemoveKey("string-1")
+	r3.Add(nil, "string-1", errors.New("timeout"))
+	return []ifs.IElements{
+		object.NewReplicaRequest(r1, 1),
+		object.NewReplicaRequest(r2, 2),
+		object.NewReplicaRequest(r3, 3),
+	}
+}
+
+// TestReconcile_MajorityVote verifies the winners, the disagreeing replicas
+// and the repairs with a majority vote.
+func TestReconcile_MajorityVote(t *testing.T) {
+	result, err := object.NewReconciler(object.NewMajorityVote(), 2).Reconcile(testReplicas()...)
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if result.Elements.Len() != 3 {
+		t.Fatalf("Expected 3 keys, got %d", result.Elements.Len())
+	}
+	winner, _ := result.Elements.ByKey("string-2")
+	if winner.(*testtypes.TestProto).MyInt64 != 20 {
+		t.Errorf("Expected the majority value, got %d", winner.(*testtypes.TestProto).MyInt64)
+	}
+	expected := map[interface{}][]byte{"string-1": {3}, "string-2": {2}, "string-3": {3}}
+	if !reflect.DeepEqual(result.Disagreed, expected) {
+		t.Errorf("Unexpected disagreements %v", result.Disagreed)
+	}
+	repair := result.Repairs[3]
+	if repair == nil || !repair.IsReplica() || repair.Replica() != 3 || repair.Len() != 2 {
+		t.Fatalf("Unexpected repair for replica 3")
+	}
+	if !repair.HasKey("string-1") || !repair.HasKey("string-3") {
+		t.Errorf("Unexpected repair keys %v", repair.Keys())
+	}
+	if result.Repairs[1] != nil {
+		t.Errorf("Expected no repair for replica 1")
+	}
+}
+
+// TestReconcile_LastWriterWins verifies version paths, metadata versions and
+// the quorum.
+func TestReconcile_LastWriterWins(t *testing.T) {
+	result, err := object.NewReconciler(object.NewLastWriterWins("MyInt64"), 2).Reconcile(testReplicas()...)
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	winner, _ := result.Elements.ByKey("string-2")
+	if winner.(*testtypes.TestProto).MyInt64 != 99 {
+		t.Errorf("Expected the highest version to win")
+	}
+	if !reflect.DeepEqual(result.Disagreed["string-2"], []byte{1, 3}) {
+		t.Errorf("Unexpected disagreements %v", result.Disagreed["string-2"])
+	}
+
+	responses := testReplicas()
+	responses[0].(*object.Elements).Merge(object.NewQueryResult(nil, &l8api.L8MetaData{KeyCount: &l8api.L8Count{
+		Counts: map[string]float64{object.CountVersion: 7}}}), object.MergeAppend)
+	result, err = object.NewReconciler(object.NewLastWriterWins(""), 3).Reconcile(responses...)
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	winner, _ = result.Elements.ByKey("string-2")
+	if winner.(*testtypes.TestProto).MyInt64 != 20 {
+		t.Errorf("Expected the response with the highest metadata version to win")
+	}
+	if result.Elements.ErrorByKey("string-1") != object.ErrNoQuorum {
+		t.Errorf("Expected no quorum for string-1")
+	}
+
+	_, err = object.NewReconciler(object.NewMajorityVote(), 4).Reconcile(testReplicas()...)
+	if err == nil {
+		t.Errorf("Expected an error for fewer responses than the quorum")
+	}
+}
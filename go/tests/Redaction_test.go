@@ -0,0 +1,86 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	. "github.com/saichler/l8test/go/infra/t_resources"
+	"github.com/saichler/l8types/go/testtypes"
+)
+
+// TestRedaction_Elements verifies that selected fields are blanked or hashed
+// in the serialized output, including nested messages, and that the
+// caller's messages are left untouched.
+func TestRedaction_Elements(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	policy := object.NewRedactionPolicy().
+		Redact("TestProto", "MyString", object.RedactHash).
+		Redact("TestProto", "my_single.my_string", object.RedactBlank).
+		Redact("TestProtoSub", "myInt64", object.RedactBlank)
+
+	original := CreateTestModelInstance(1)
+	originalString := original.MyString
+	data, err := object.New(nil, []*testtypes.TestProto{original, nil}).(*object.Elements).SerializeRedacted(policy)
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	if original.MyString != originalString || original.MySingle.MyString == "" || original.MySingle.MyInt64 == 0 {
+		t.Fatal("Caller's message was mutated")
+	}
+
+	elems := &object.Elements{}
+	err = elems.Deserialize(data, globals.Registry())
+	if err != nil {
+		t.Fatalf("Failed to deserialize: %v", err)
+	}
+	redacted := elems.Elements()[0].(*testtypes.TestProto)
+	if !strings.HasPrefix(redacted.MyString, "sha256:") {
+		t.Errorf("Expected hashed string, got %s", redacted.MyString)
+	}
+	if redacted.MySingle.MyString != "" || redacted.MySingle.MyInt64 != 0 {
+		t.Error("Expected nested fields to be blanked")
+	}
+	if redacted.MyInt32 != original.MyInt32 {
+		t.Error("Expected fields outside the policy to be kept")
+	}
+}
+
+// TestRedaction_Object verifies redaction through Object.SetRedaction for
+// maps of messages.
+func TestRedaction_Object(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	policy := object.NewRedactionPolicy().Redact("TestProto", "mystring", object.RedactBlank)
+	val := map[string]*testtypes.TestProto{"a": CreateTestModelInstance(1)}
+
+	obj := object.NewEncode()
+	obj.SetRedaction(policy)
+	err := obj.Add(val)
+	if err != nil {
+		t.Fatalf("Failed to add: %v", err)
+	}
+	result, err := object.NewDecode(obj.Data(), 0, globals.Registry()).Get()
+	if err != nil {
+		t.Fatalf("Failed to get: %v", err)
+	}
+	if result.(map[string]*testtypes.TestProto)["a"].MyString != "" {
+		t.Error("Expected field to be blanked")
+	}
+	if val["a"].MyString == "" {
+		t.Error("Caller's message was mutated")
+	}
+}
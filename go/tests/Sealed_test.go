@@ -0,0 +1,99 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/testtypes"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestSealed_SecurityProvider verifies sealing through the security provider
+// and that a modified payload is rejected.
+func TestSealed_SecurityProvider(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	list := testElementsList(5)
+	data, err := object.New(nil, list).(*object.Elements).SerializeSealed(globals)
+	if err != nil {
+		t.Fatalf("Failed to seal: %v", err)
+	}
+	if !object.IsSealed(data) {
+		t.Fatal("Expected sealed payload")
+	}
+	if bytes.Contains(data, []byte(list[0].MyString)) {
+		t.Fatal("Sealed payload contains plaintext")
+	}
+
+	elems := &object.Elements{}
+	err = elems.DeserializeSealed(data, globals)
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	for i, elem := range elems.Elements() {
+		if !proto.Equal(elem.(proto.Message), list[i]) {
+			t.Fatalf("Element mismatch at index %d", i)
+		}
+	}
+
+	for _, i := range []int{len(data) / 2, len(data) - 1} {
+		tampered := append([]byte(nil), data...)
+		tampered[i] ^= 0x01
+		err = (&object.Elements{}).DeserializeSealed(tampered, globals)
+		if !errors.Is(err, object.ErrSealTampered) {
+			t.Fatalf("Expected tampered error at byte %d, got %v", i, err)
+		}
+	}
+}
+
+// TestSealed_AESGCM verifies sealing with a caller supplied key, rejection
+// of modified payloads, of a wrong key and of unsealed payloads.
+func TestSealed_AESGCM(t *testing.T) {
+	globals.Registry().Register(&testtypes.TestProto{})
+	sealer, err := object.NewAESGCMSealer([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Failed to create sealer: %v", err)
+	}
+	data, err := object.New(nil, testElementsList(3)).(*object.Elements).SerializeSealedWith(sealer)
+	if err != nil {
+		t.Fatalf("Failed to seal: %v", err)
+	}
+	elems := &object.Elements{}
+	err = elems.DeserializeSealedWith(data, sealer, globals.Registry())
+	if err != nil || len(elems.Elements()) != 3 {
+		t.Fatalf("Failed to open: %v", err)
+	}
+
+	other, _ := object.NewAESGCMSealer([]byte("fedcba9876543210fedcba9876543210"))
+	err = (&object.Elements{}).DeserializeSealedWith(data, other, globals.Registry())
+	if !errors.Is(err, object.ErrSealTampered) {
+		t.Fatalf("Expected tampered error for wrong key, got %v", err)
+	}
+
+	data[len(data)-1] ^= 0x01
+	err = (&object.Elements{}).DeserializeSealedWith(data, sealer, globals.Registry())
+	if !errors.Is(err, object.ErrSealTampered) {
+		t.Fatalf("Expected tampered error, got %v", err)
+	}
+
+	plain, _ := object.New(nil, testElementsList(1)).Serialize()
+	err = (&object.Elements{}).DeserializeSealedWith(plain, sealer, globals.Registry())
+	if !errors.Is(err, object.ErrNotSealed) {
+		t.Fatalf("Expected not sealed error, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tests
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+)
+
+// timeSeriesRoundTrip encodes a value with AddTimeSeries and decodes it with Get.
+func timeSeriesRoundTrip(t *testing.T, val interface{}) (interface{}, int) {
+	obj := object.NewEncode()
+	err := obj.AddTimeSeries(val)
+	if err != nil {
+		t.Fatalf("Failed to add time series: %v", err)
+	}
+	data := obj.Data()
+	result, err := object.NewDecode(data, 0, globals.Registry()).Get()
+	if err != nil {
+		t.Fatalf("Failed to get time series: %v", err)
+	}
+	return result, len(data)
+}
+
+// TestTimeSeries_Timestamps tests delta-of-delta encoding of minute
+// resolution timestamps with a few irregular gaps and large jumps.
+func TestTimeSeries_Timestamps(t *testing.T) {
+	val := make([]int64, 1440)
+	ts := int64(1700000000000)
+	for i := range val {
+		ts += 60000
+		if i%100 == 0 {
+			ts += int64(i * 3)
+		}
+		if i == 700 {
+			ts += math.MaxInt32
+		}
+		val[i] = ts
+	}
+	val[1000] = -val[1000]
+
+	result, size := timeSeriesRoundTrip(t, val)
+	res := result.([]int64)
+	if len(res) != len(val) {
+		t.Fatalf("Length mismatch: expected %d, got %d", len(val), len(res))
+	}
+	for i := range val {
+		if res[i] != val[i] {
+			t.Fatalf("Value mismatch at index %d: expected %d, got %d", i, val[i], res[i])
+		}
+	}
+	if size*10 > len(val)*8 {
+		t.Errorf("Expected at least 10x reduction, got %d bytes for %d values", size, len(val))
+	}
+}
+
+// TestTimeSeries_Samples tests XOR encoding of float samples including
+// repeated values and special values.
+func TestTimeSeries_Samples(t *testing.T) {
+	val := make([]float64, 1440)
+	for i := range val {
+		val[i] = 20 + float64(i/30)*0.5
+	}
+	val[10] = math.Inf(1)
+	val[11] = -0.0
+	val[12] = math.MaxFloat64
+	val[13] = math.SmallestNonzeroFloat64
+
+	result, _ := timeSeriesRoundTrip(t, val)
+	res := result.([]float64)
+	if len(res) != len(val) {
+		t.Fatalf("Length mismatch: expected %d, got %d", len(val), len(res))
+	}
+	for i := range val {
+		if math.Float64bits(res[i]) != math.Float64bits(val[i]) {
+			t.Fatalf("Value mismatch at index %d: expected %v, got %v", i, val[i], res[i])
+		}
+	}
+}
+
+// TestTimeSeries_EmptyAndInvalid tests empty slices, unsupported types and
+// truncated data.
+func TestTimeSeries_EmptyAndInvalid(t *testing.T) {
+	result, _ := timeSeriesRoundTrip(t, []int64{})
+	if result != nil {
+		t.Error("Expected nil result for empty slice")
+	}
+	obj := object.NewEncode()
+	if obj.AddTimeSeries([]int32{1, 2}) == nil {
+		t.Error("Expected error for []int32")
+	}
+	if obj.AddTimeSeries(nil) == nil {
+		t.Error("Expected error for nil")
+	}
+
+	obj = object.NewEncode()
+	obj.AddTimeSeries([]float64{1.5, 2.25, 3.125, 100.5})
+	data := obj.Data()
+	// Drop the last encoded byte, keeping the encoded length as written
+	truncated := append([]byte(nil), data[:len(data)-1]...)
+	_, err := object.NewDecode(truncated, 0, globals.Registry()).Get()
+	if err == nil {
+		t.Error("Expected error for truncated time series")
+	}
+	// Claim more values than the encoded bits hold
+	short := append([]byte(nil), data...)
+	binary.BigEndian.PutUint32(short[4:], uint32(len(data)*8-8))
+	_, err = object.NewDecode(short, 0, globals.Registry()).Get()
+	if err == nil {
+		t.Error("Expected error for a size beyond the encoded bits")
+	}
+}